@@ -2,20 +2,30 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
+	"math/bits"
+	"math/rand"
 	"os"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type Operation int
 
 const (
-	MAX_STACK_SIZE = 999_999
+	MAX_STACK_SIZE      = 999_999
+	MAX_LOOP_ITERATIONS = 1_000_000
+	MAX_CALL_DEPTH      = 1_000
+	FLOAT_EPSILON       = 1e-9
 )
 
 const (
@@ -28,13 +38,26 @@ const (
 	EXP_OP
 	INC_OP
 	DEC_OP
+	NEG_OP
+	ABS_OP
+	SQRT_OP
 
 	// Stack manipulation operations
 	SWAP_OP
 	DUP_OP
 	DROP_OP
 	DUMP_OP
+	POP_PRINT_OP
+	EMIT_OP
 	ROT_OP
+	ROT_BACK_OP
+	PICK_OP
+	ROLL_OP
+	FILL_OP
+	CLEARNUMS_OP
+	DROPDUP_OP
+	STACKCOUNT_OP
+	STACKLIST_OP
 
 	// Print operation
 	PRINT_OP
@@ -50,37 +73,236 @@ const (
 	LS_THAN_OP
 	GT_THAN_EQ_OP
 	LS_THAN_EQ_OP
+	BETWEEN_OP
+
+	// Bitwise operations
+	BIT_AND_OP
+	BIT_OR_OP
+	BIT_XOR_OP
+	SHIFT_LEFT_OP
+	SHIFT_RIGHT_OP
 
 	// assignment operation
 	VAR_ASSIGN_OP
+
+	// Number theory operations
+	FIB_OP
+	PRIME_OP
+
+	// Bitwise operations
+	REVBITS_OP
+	POPCOUNT_OP
+	BSWAP_OP
+
+	// List operations
+	PAIR_OP
+	UNPAIR_OP
+	HEAD_OP
+	LAST_OP
+	TAIL_OP
+	CONS_OP
+	LEN_OP
+	INDEX_OP
+	APPEND_OP
+	DOT_OP
+	TABULATE_OP
+	APPLY_OP
+	IFTE_OP
+
+	// Debugging operations
+	GOVTYPE_OP
+
+	// Testing operations
+	ASSERT_EQ_OP
+	ASSERT_MSG_OP
+
+	// Statistics operations
+	MEAN_OP
+
+	// Register operations
+	STORE_OP
+	LOAD_OP
+	SWAPREGS_OP
+	SAVE_STACK_OP
+	LOAD_STACK_OP
+
+	// Float operations
+	ISNAN_OP
+	ISINF_OP
+
+	// Input operations
+	READ_OP
+	READFIELDS_OP
+
+	// Formatting operations
+	COMMAS_OP
+
+	// Conversion operations
+	TO_INT_OP
+	TO_FLOAT_OP
+	TO_STR_OP
+	BOOL2STR_OP
+	STR2BOOL_OP
+
+	// Random operations
+	SEED_OP
+	LSHUFFLE_OP
+
+	// Control flow operations
+	RETURN_OP
+	ARGMIN_OP
+	ARGMAX_OP
+	LTAKE_OP
+	LDROP_OP
+	CHUNK_OP
+	DURATION_OP
+	DATEFMT_OP
+	SLEEP_OP
+	DIGITSUM_OP
+	REVDIGITS_OP
+	LCP_OP
+	HAMMING_OP
+	SUBSTR_OP
+	UPPER_OP
+	LOWER_OP
+	SPLIT_OP
+	JOIN_OP
+	INTERLEAVE_OP
+	LDIFF_OP
+	LUNION_OP
+	LINTERSECT_OP
+	TRANSPOSE_OP
+	LCLAMP_OP
+	LSCALE_OP
+	CUMSUM_OP
+	STDDEV_OP
+	HISTOGRAM_OP
+	ENUMERATE_OP
+	CONTAINS_OP
+	TOMAP_OP
+	MKEYS_OP
+	MVALUES_OP
+	MODE_OP
 )
 
 var operatorMap = map[string]Operation{
-	"+":     ADD_OP,
-	"-":     SUB_OP,
-	"*":     MUL_OP,
-	"/":     DIV_OP,
-	"%":     MOD_OP,
-	"^":     EXP_OP,
-	"++":    INC_OP,
-	"--":    DEC_OP,
-	"swap":  SWAP_OP,
-	"dup":   DUP_OP,
-	"drop":  DROP_OP,
-	"dump":  DUMP_OP,
-	"print": PRINT_OP,
-	"rot":   ROT_OP,
-	"&&":    AND_OP,
-	"||":    OR_OP,
-	"!":     NOT_OP,
-	"==":    EQUAL_OP,
-	"!=":    NOT_EQUAL_OP,
-	"===":   EQUAL_TYP_OP,
-	">":     GT_THAN_OP,
-	"<":     LS_THAN_OP,
-	">=":    GT_THAN_EQ_OP,
-	"<=":    LS_THAN_EQ_OP,
-	"=":     VAR_ASSIGN_OP,
+	"+":          ADD_OP,
+	"-":          SUB_OP,
+	"*":          MUL_OP,
+	"/":          DIV_OP,
+	"%":          MOD_OP,
+	"^":          EXP_OP,
+	"++":         INC_OP,
+	"--":         DEC_OP,
+	"neg":        NEG_OP,
+	"abs":        ABS_OP,
+	"sqrt":       SQRT_OP,
+	"swap":       SWAP_OP,
+	"dup":        DUP_OP,
+	"drop":       DROP_OP,
+	"dump":       DUMP_OP,
+	".":          POP_PRINT_OP,
+	"emit":       EMIT_OP,
+	"print":      PRINT_OP,
+	"rot":        ROT_OP,
+	"-rot":       ROT_BACK_OP,
+	"pick":       PICK_OP,
+	"roll":       ROLL_OP,
+	"fill":       FILL_OP,
+	"clearnums":  CLEARNUMS_OP,
+	"dropdup":    DROPDUP_OP,
+	"stackcount": STACKCOUNT_OP,
+	"stacklist":  STACKLIST_OP,
+	"&&":         AND_OP,
+	"||":         OR_OP,
+	"!":          NOT_OP,
+	"==":         EQUAL_OP,
+	"!=":         NOT_EQUAL_OP,
+	"===":        EQUAL_TYP_OP,
+	">":          GT_THAN_OP,
+	"<":          LS_THAN_OP,
+	">=":         GT_THAN_EQ_OP,
+	"<=":         LS_THAN_EQ_OP,
+	"between":    BETWEEN_OP,
+	"&":          BIT_AND_OP,
+	"|":          BIT_OR_OP,
+	"xor":        BIT_XOR_OP,
+	"<<":         SHIFT_LEFT_OP,
+	">>":         SHIFT_RIGHT_OP,
+	"=":          VAR_ASSIGN_OP,
+	"fib":        FIB_OP,
+	"prime?":     PRIME_OP,
+	"revbits":    REVBITS_OP,
+	"popcount":   POPCOUNT_OP,
+	"bswap":      BSWAP_OP,
+	"pair":       PAIR_OP,
+	"unpair":     UNPAIR_OP,
+	"head":       HEAD_OP,
+	"last":       LAST_OP,
+	"tail":       TAIL_OP,
+	"cons":       CONS_OP,
+	"len":        LEN_OP,
+	"index":      INDEX_OP,
+	"append":     APPEND_OP,
+	"dot":        DOT_OP,
+	"tabulate":   TABULATE_OP,
+	"apply":      APPLY_OP,
+	"ifte":       IFTE_OP,
+	"govtype":    GOVTYPE_OP,
+	"asserteq":   ASSERT_EQ_OP,
+	"assertmsg":  ASSERT_MSG_OP,
+	"mean":       MEAN_OP,
+	"store":      STORE_OP,
+	"load":       LOAD_OP,
+	"swapregs":   SWAPREGS_OP,
+	"savestack":  SAVE_STACK_OP,
+	"loadstack":  LOAD_STACK_OP,
+	"nan?":       ISNAN_OP,
+	"inf?":       ISINF_OP,
+	"read":       READ_OP,
+	"readfields": READFIELDS_OP,
+	"commas":     COMMAS_OP,
+	"toint":      TO_INT_OP,
+	"tofloat":    TO_FLOAT_OP,
+	"tostr":      TO_STR_OP,
+	"bool2str":   BOOL2STR_OP,
+	"str2bool":   STR2BOOL_OP,
+	"seed":       SEED_OP,
+	"lshuffle":   LSHUFFLE_OP,
+	"return":     RETURN_OP,
+	"argmin":     ARGMIN_OP,
+	"argmax":     ARGMAX_OP,
+	"ltake":      LTAKE_OP,
+	"ldrop":      LDROP_OP,
+	"chunk":      CHUNK_OP,
+	"duration":   DURATION_OP,
+	"datefmt":    DATEFMT_OP,
+	"sleep":      SLEEP_OP,
+	"digitsum":   DIGITSUM_OP,
+	"revdigits":  REVDIGITS_OP,
+	"lcp":        LCP_OP,
+	"hamming":    HAMMING_OP,
+	"substr":     SUBSTR_OP,
+	"upper":      UPPER_OP,
+	"lower":      LOWER_OP,
+	"split":      SPLIT_OP,
+	"join":       JOIN_OP,
+	"interleave": INTERLEAVE_OP,
+	"ldiff":      LDIFF_OP,
+	"lunion":     LUNION_OP,
+	"lintersect": LINTERSECT_OP,
+	"transpose":  TRANSPOSE_OP,
+	"lclamp":     LCLAMP_OP,
+	"lscale":     LSCALE_OP,
+	"cumsum":     CUMSUM_OP,
+	"stddev":     STDDEV_OP,
+	"histogram":  HISTOGRAM_OP,
+	"enumerate":  ENUMERATE_OP,
+	"contains":   CONTAINS_OP,
+	"tomap":      TOMAP_OP,
+	"mkeys":      MKEYS_OP,
+	"mvalues":    MVALUES_OP,
+	"mode":       MODE_OP,
 }
 
 type Type int
@@ -94,16 +316,26 @@ const (
 	Identifier
 	SpecialSymbol
 	KeyWord
+	List
+	// Quotation is a block of code written as { ... } that is pushed instead of run,
+	// operators such as tabulate run it
+	Quotation
+	// Map holds a map[string]StackElement, it is built from a list of pairs with tomap
+	Map
 )
 
 var typeMap = map[Type]string{
 	Int:           "int",
 	String:        "string",
 	Bool:          "bool",
+	Float:         "float",
 	Operator:      "operator",
 	Identifier:    "identifier",
 	SpecialSymbol: "special symbol",
 	KeyWord:       "keyword",
+	List:          "list",
+	Quotation:     "quotation",
+	Map:           "map",
 }
 
 type StackElement struct {
@@ -115,6 +347,144 @@ func (s *StackElement) Repr() string {
 	return fmt.Sprintf("Type: %v\nValue: %v", typeMap[s.Type], s.Value)
 }
 
+// String formats the element as its type name and value, eg. int(15) or string("hi"),
+// so printed stacks are readable instead of showing the raw type number
+func (s StackElement) String() string {
+	switch s.Type {
+	case String:
+		return fmt.Sprintf("%v(%q)", typeMap[s.Type], s.Value)
+	case Operator:
+		if op, ok := s.Value.(Operation); ok {
+			return fmt.Sprintf("%v(%v)", typeMap[s.Type], operatorName(op))
+		}
+	}
+
+	return fmt.Sprintf("%v(%v)", typeMap[s.Type], s.Value)
+}
+
+// jsonElement is how a StackElement is written in saved state, the type is kept by name
+// so values decode back to the right Go type instead of every number becoming a float64
+type jsonElement struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (s StackElement) MarshalJSON() ([]byte, error) {
+	name, exists := typeMap[s.Type]
+	if !exists {
+		return nil, fmt.Errorf("ERROR: cannot serialize elements of type %d", s.Type)
+	}
+
+	value := s.Value
+	switch s.Type {
+	case Operator:
+		value = operatorName(s.Value.(Operation))
+	case KeyWord:
+		// procedure definitions only live in programs, not on the stack
+		if _, ok := s.Value.(string); !ok {
+			return nil, errors.New("ERROR: cannot serialize a procedure definition")
+		}
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonElement{Type: name, Value: raw})
+}
+
+func (s *StackElement) UnmarshalJSON(data []byte) error {
+	var element jsonElement
+	err := json.Unmarshal(data, &element)
+	if err != nil {
+		return err
+	}
+
+	typ, exists := typeByName(element.Type)
+	if !exists {
+		return fmt.Errorf("ERROR: unknown element type %q", element.Type)
+	}
+
+	var value interface{}
+	switch typ {
+	case Int:
+		var n int
+		err = json.Unmarshal(element.Value, &n)
+		value = n
+	case Float:
+		var f float64
+		err = json.Unmarshal(element.Value, &f)
+		value = f
+	case Bool:
+		var b bool
+		err = json.Unmarshal(element.Value, &b)
+		value = b
+	case String, Identifier, SpecialSymbol, KeyWord:
+		var str string
+		err = json.Unmarshal(element.Value, &str)
+		value = str
+	case Operator:
+		var name string
+		err = json.Unmarshal(element.Value, &name)
+		op, exists := operatorMap[name]
+		if err == nil && !exists {
+			return fmt.Errorf("ERROR: unknown operator %q", name)
+		}
+		value = op
+	case List, Quotation:
+		items := []StackElement{}
+		err = json.Unmarshal(element.Value, &items)
+		value = items
+	case Map:
+		entries := map[string]StackElement{}
+		err = json.Unmarshal(element.Value, &entries)
+		value = entries
+	}
+	if err != nil {
+		return err
+	}
+
+	*s = StackElement{Type: typ, Value: value}
+	return nil
+}
+
+// typeByName is the reverse of typeMap
+func typeByName(name string) (Type, bool) {
+	for typ, typName := range typeMap {
+		if typName == name {
+			return typ, true
+		}
+	}
+	return 0, false
+}
+
+// copyElement returns a deep copy of an element so that lists and maps are never shared
+// between two places on the stack
+func copyElement(val StackElement) StackElement {
+	if val.Type == Map {
+		entries := val.Value.(map[string]StackElement)
+		copied := make(map[string]StackElement, len(entries))
+		for key, entry := range entries {
+			copied[key] = copyElement(entry)
+		}
+
+		return StackElement{Type: Map, Value: copied}
+	}
+
+	if val.Type != List {
+		return val
+	}
+
+	items := val.Value.([]StackElement)
+	copied := make([]StackElement, len(items))
+	for i, item := range items {
+		copied[i] = copyElement(item)
+	}
+
+	return StackElement{Type: List, Value: copied}
+}
+
 type Variable struct {
 	Type  Type
 	Value interface{}
@@ -122,20 +492,256 @@ type Variable struct {
 	Const bool
 }
 
+// Procedure is a named sequence of tokens defined with def name ... end. Tokenize emits it as
+// a KeyWord so executing the definition registers the body in Gorth.Procedures
+type Procedure struct {
+	Name string
+	Body []StackElement
+}
+
+// Declaration is a variable declared inside a scope ... end block. Tokenize emits it as a KeyWord
+// so the variable is declared in the innermost scope when the program reaches it
+type Declaration struct {
+	Variable Variable
+}
+
 type Gorth struct {
-	ExecStack    []StackElement
+	ExecStack []StackElement
+	// VariableMap holds the variables declared outside of any scope, it is the bottom of the scope
+	// stack. It stays a field of its own because saved state and callers of ExecuteProgram set it
+	// directly, lookups still search it through findScope like every other scope
 	VariableMap  map[string]Variable
 	DebugMode    bool
 	StrictMode   bool
 	MaxStackSize int
+	// MaxLoopIterations bounds how many times a single while loop can run its body
+	MaxLoopIterations int
+	// RejectNonFinite makes arithmetic error instead of pushing a NaN or ±Inf float
+	RejectNonFinite bool
+	// Epsilon is how far apart two floats can be and still compare as equal, 0 compares them exactly
+	Epsilon   float64
+	Registers map[string]StackElement
+	// Procedures holds the bodies of the procedures defined with def name ... end
+	Procedures map[string][]StackElement
+	// MaxCallDepth bounds how deeply procedure calls can nest, which stops runaway recursion
+	MaxCallDepth int
+	// MaxSteps bounds how many tokens a single run can execute in total, 0 means unlimited
+	MaxSteps int
+	// Input is where read takes its lines from, it defaults to os.Stdin
+	Input io.Reader
+	// Out is where the printing operations write to, it defaults to os.Stdout
+	Out io.Writer
+	// Rand is used by the random operations, seed replaces it with a seeded source
+	Rand *rand.Rand
+	// Now is the clock used by the date operations, it defaults to time.Now
+	Now func() time.Time
+	// AllowSleep enables the sleep operation, sandboxed runs can turn it off
+	AllowSleep bool
+	// Sleep pauses execution for sleep, it defaults to time.Sleep
+	Sleep func(time.Duration)
+	// DynamicTyping lets = assign a value of a different type to a variable, changing its type
+	DynamicTyping bool
+	// DisabledOps lists operators programs are not allowed to use, eg. to sandbox untrusted scripts
+	DisabledOps map[Operation]bool
+
+	// the rest of the scope stack, the variables declared in each open scope ... end block
+	scopes []map[string]Variable
+
+	// the element being executed and its position, used to report panics
+	current  StackElement
+	position int
+	// number of procedure calls currently running
+	callDepth int
+	// buffers Input across reads, it is rebuilt when Input is replaced
+	reader       *bufio.Reader
+	readerSource io.Reader
+
+	// execution metrics, reset at the start of every ExecuteProgram call
+	instructionCount int
+	opCounts         map[Operation]int
+	stackHighWater   int
+	duration         time.Duration
+}
+
+// Sentinel errors wrapped by the errors that operations return, so programs embedding
+// Gorth can check for them with errors.Is instead of matching messages
+var (
+	ErrStackOverflow      = errors.New("stack overflow")
+	ErrStackEmpty         = errors.New("stack is empty")
+	ErrDivideByZero       = errors.New("divide by zero")
+	ErrTypeMismatch       = errors.New("type mismatch")
+	ErrUndeclaredVariable = errors.New("undeclared variable")
+)
+
+// errReturn is returned by execute when it runs RETURN_OP, it unwinds to the procedure call
+// or the program being run and is never reported as an error
+var errReturn = errors.New("return")
+
+// sentinelError keeps the message of an error while letting errors.Is match its sentinel
+type sentinelError struct {
+	sentinel error
+	message  string
+}
+
+func (e *sentinelError) Error() string {
+	return e.message
+}
+
+func (e *sentinelError) Unwrap() error {
+	return e.sentinel
+}
+
+// wrapError formats a message like fmt.Errorf and wraps sentinel without adding it to the message
+func wrapError(sentinel error, format string, args ...interface{}) error {
+	return &sentinelError{sentinel: sentinel, message: fmt.Sprintf(format, args...)}
+}
+
+type ErrorKind int
+
+const (
+	// InternalError means the interpreter itself failed, eg. an operator panicked
+	InternalError ErrorKind = iota
+)
+
+var errorKindMap = map[ErrorKind]string{
+	InternalError: "internal error",
+}
+
+// GorthError is an error raised by the interpreter, along with the element, program position
+// and stack depth it was raised at
+type GorthError struct {
+	Kind ErrorKind
+	// Operation is only meaningful when HasOperation is set, the zero value is ADD_OP
+	Operation    Operation
+	HasOperation bool
+	// Element is the token that was being executed
+	Element  StackElement
+	Position int
+	// StackDepth is how many elements were on the stack when the error was raised
+	StackDepth int
+	Message    string
+}
+
+func (e *GorthError) Error() string {
+	where := e.Element.String()
+	if e.HasOperation {
+		where = operatorName(e.Operation)
+	}
+
+	return fmt.Sprintf("ERROR: %v in %v at position %d with stack depth %d: %v", errorKindMap[e.Kind], where, e.Position, e.StackDepth, e.Message)
+}
+
+// ExecMetrics is a snapshot of the metrics collected during the last ExecuteProgram call
+type ExecMetrics struct {
+	Instructions  int            `json:"instructions"`
+	OpCounts      map[string]int `json:"op_counts"`
+	MaxStackDepth int            `json:"max_stack_depth"`
+	Duration      time.Duration  `json:"duration_ns"`
 }
 
 func NewGorth(debugMode, strictMode bool) *Gorth {
 	return &Gorth{
-		ExecStack:    []StackElement{},
-		DebugMode:    debugMode,
-		StrictMode:   strictMode,
-		MaxStackSize: MAX_STACK_SIZE,
+		ExecStack:         []StackElement{},
+		VariableMap:       make(map[string]Variable),
+		DebugMode:         debugMode,
+		StrictMode:        strictMode,
+		MaxStackSize:      MAX_STACK_SIZE,
+		MaxLoopIterations: MAX_LOOP_ITERATIONS,
+		Registers:         make(map[string]StackElement),
+		Procedures:        make(map[string][]StackElement),
+		MaxCallDepth:      MAX_CALL_DEPTH,
+		Epsilon:           FLOAT_EPSILON,
+		Input:             os.Stdin,
+		Out:               os.Stdout,
+		Rand:              rand.New(rand.NewSource(time.Now().UnixNano())),
+		Now:               time.Now,
+		AllowSleep:        true,
+		Sleep:             time.Sleep,
+		opCounts:          make(map[Operation]int),
+	}
+}
+
+// Reset clears the stack, variables, registers and procedures so the instance can run another
+// program. Options such as DebugMode, StrictMode and MaxStackSize are kept
+func (g *Gorth) Reset() {
+	g.ExecStack = []StackElement{}
+	g.VariableMap = make(map[string]Variable)
+	g.scopes = nil
+	g.Registers = make(map[string]StackElement)
+	g.Procedures = make(map[string][]StackElement)
+	g.instructionCount = 0
+	g.opCounts = make(map[Operation]int)
+	g.stackHighWater = 0
+	g.duration = 0
+}
+
+// savedState is the JSON form of the stack and variables written by MarshalState
+type savedState struct {
+	Stack     []StackElement           `json:"stack"`
+	Variables map[string]savedVariable `json:"variables,omitempty"`
+}
+
+type savedVariable struct {
+	Value StackElement `json:"value"`
+	Const bool         `json:"const"`
+}
+
+// MarshalState serializes the stack and variables to JSON, so a run can be snapshotted and
+// restored later with LoadState
+func (g *Gorth) MarshalState() ([]byte, error) {
+	state := savedState{Stack: g.ExecStack, Variables: make(map[string]savedVariable, len(g.VariableMap))}
+	for name, variable := range g.VariableMap {
+		state.Variables[name] = savedVariable{Value: StackElement{Type: variable.Type, Value: variable.Value}, Const: variable.Const}
+	}
+
+	return json.Marshal(state)
+}
+
+// LoadState replaces the stack and variables with ones saved by MarshalState, on error
+// both are left unchanged
+func (g *Gorth) LoadState(data []byte) error {
+	var state savedState
+	err := json.Unmarshal(data, &state)
+	if err != nil {
+		return err
+	}
+
+	variables := make(map[string]Variable, len(state.Variables))
+	for name, variable := range state.Variables {
+		variables[name] = Variable{Type: variable.Value.Type, Value: variable.Value.Value, Name: name, Const: variable.Const}
+	}
+
+	g.ExecStack = state.Stack
+	if g.ExecStack == nil {
+		g.ExecStack = []StackElement{}
+	}
+	g.VariableMap = variables
+	return nil
+}
+
+// operatorName returns the token used to write an operation in a program
+func operatorName(op Operation) string {
+	for name, o := range operatorMap {
+		if o == op {
+			return name
+		}
+	}
+	return fmt.Sprintf("%d", op)
+}
+
+// Metrics returns the instruction count, per operator counts, stack high-water mark
+// and wall-clock duration of the last program run
+func (g *Gorth) Metrics() ExecMetrics {
+	opCounts := make(map[string]int, len(g.opCounts))
+	for op, count := range g.opCounts {
+		opCounts[operatorName(op)] = count
+	}
+
+	return ExecMetrics{
+		Instructions:  g.instructionCount,
+		OpCounts:      opCounts,
+		MaxStackDepth: g.stackHighWater,
+		Duration:      g.duration,
 	}
 }
 
@@ -150,10 +756,7 @@ func ReadGorthFile(filename string) ([]string, error) {
 	var lines []string
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		// if line starts with a comment, ignore it
-		if strings.HasPrefix(scanner.Text(), "#") {
-			continue
-		}
+		// comments are kept, Tokenize strips them so a block comment can span lines
 		lines = append(lines, scanner.Text())
 	}
 
@@ -164,9 +767,63 @@ func ReadGorthFile(filename string) ([]string, error) {
 	return lines, nil
 }
 
+// StripComments removes the comments that are not inside a string literal. A # comment runs to
+// the end of its line, eg. 5 dup + # double it becomes 5 dup +, and a (* ... *) comment can span
+// lines and is replaced with a space so the code around it stays separate tokens, eg. 1 (* one *) 2 +
+// becomes 1   2 +. Both are stripped in one pass so a # inside a block comment, or a (* after a #,
+// is part of the comment
+func StripComments(source string) (string, error) {
+	var stripped strings.Builder
+	inString := false
+	line, column := 1, 1
+	for i := 0; i < len(source); i++ {
+		switch {
+		case source[i] == '"':
+			inString = !inString
+		case !inString && source[i] == '#':
+			// the newline is kept so the lines after the comment keep their numbers
+			end := strings.IndexByte(source[i:], '\n')
+			if end < 0 {
+				return stripped.String(), nil
+			}
+
+			i += end - 1
+			continue
+		case !inString && strings.HasPrefix(source[i:], "(*"):
+			end := strings.Index(source[i+2:], "*)")
+			if end < 0 {
+				return "", fmt.Errorf("ERROR: block comment opened at line %d, column %d is never closed", line, column)
+			}
+
+			comment := source[i : i+2+end+2]
+			if newlines := strings.Count(comment, "\n"); newlines > 0 {
+				line += newlines
+				column = utf8.RuneCountInString(comment[strings.LastIndex(comment, "\n")+1:]) + 1
+			} else {
+				column += utf8.RuneCountInString(comment)
+			}
+
+			stripped.WriteByte(' ')
+			i += len(comment) - 1
+			continue
+		}
+
+		if source[i] == '\n' {
+			line++
+			column = 1
+		} else if utf8.RuneStart(source[i]) {
+			column++
+		}
+		stripped.WriteByte(source[i])
+	}
+
+	return stripped.String(), nil
+}
+
 const (
 	StateNormal = iota
 	StateVarDeclaration
+	StateProcedureName
 )
 
 type Tokeniser struct {
@@ -184,26 +841,80 @@ func (t *TokeniserStateMachine) SetState(state int) {
 
 // Tokenizer
 func Tokenize(s string) ([]StackElement, map[string]Variable, error) {
+	s, err := StripComments(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var tokens []StackElement
 	var lastAddedVariable Variable
 	variables := make(map[string]Variable)
 
 	// Define regex patterns
 	integerRegex := regexp.MustCompile(`^-?\d+$`)
+	// hex and binary literals are checked before integerRegex, so 0b1010 is never read as 0
+	hexRegex := regexp.MustCompile(`^-?0x[0-9a-fA-F]+$`)
+	binaryRegex := regexp.MustCompile(`^-?0b[01]+$`)
 	floatRegex := regexp.MustCompile(`^-?\d+\.\d+$`)
 	stringRegex := regexp.MustCompile(`^".*"$`)
 	boolRegex := regexp.MustCompile(`^(true|false)$`)
-	operatorRegex := regexp.MustCompile(`^(\+|-|\*|/|%|\^|\+\+|--|neg|swap|dup|drop|dump|print|rot|&&|\|\||!|==|!=|===|>|<|>=|<=|=)$`)
+	// multi character operators are listed before the single character operators
+	// they start with so the longer operator is always tried first
+	operatorRegex := regexp.MustCompile(`^(===|==|!=|>=|<=|&&|\|\||<<|>>|\+\+|--|-rot|\+|-|\*|/|%|\^|!|>|<|=|&|\||neg|swap|dup|drop|dump|print|rot|fib|prime\?|revbits|popcount|bswap|pair|unpair|pick|govtype|roll|asserteq|assertmsg|fill|mean|between|clearnums|store|load|swapregs|nan\?|inf\?|read|head|last|tail|cons|dropdup|commas|stackcount|toint|stacklist|tofloat|seed|lshuffle|tostr|readfields|return|bool2str|str2bool|dot|tabulate|\.|argmin|argmax|emit|ltake|ldrop|duration|datefmt|abs|sleep|sqrt|digitsum|xor|revdigits|apply|ifte|lcp|interleave|hamming|transpose|lclamp|savestack|loadstack|lscale|cumsum|len|index|append|stddev|substr|histogram|upper|lower|enumerate|split|chunk|join|ldiff|lunion|lintersect|contains|tomap|mkeys|mvalues|mode)$`)
 	varNameRegex := regexp.MustCompile(`^\/[a-zA-Z_][a-zA-Z0-9_]*$`)
 	varUsageRegex := regexp.MustCompile(`^_[a-zA-Z_][a-zA-Z0-9_]*$`)
 	// TODO: rename this
 	keyWordRegex := regexp.MustCompile(`^(def|const|=)$`)
+	blockRegex := regexp.MustCompile(`^(if|else|while|do|end|scope)$`)
+	procedureNameRegex := regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 	// using variables : _varName
 
 	// Split the string into tokens
 	r := regexp.MustCompile(`"[^"]*"|\S+`)
 	parts := r.FindAllString(s, -1)
 
+	// procedures defined so far, so a call can be told apart from an invalid token
+	procedures := make(map[string]bool)
+	// the procedure whose body is being tokenized, and where its body starts in tokens
+	var procedureName string
+	procedureStart := 0
+	inProcedure := false
+	// blocks opened inside the current procedure body, so their end does not close the procedure
+	procedureBlocks := 0
+	// a def right after a declared value terminates the declaration instead of starting a procedure
+	terminatesDeclaration := false
+	// a /name that was already declared and where the tokens after it start, so /x 2 def can be
+	// reported as a second declaration instead of a def starting a procedure
+	var redeclared string
+	redeclaredAt := 0
+	// where each open quotation starts in tokens, innermost last
+	var quotationStarts []int
+	// how many quotations were open when the current procedure started
+	procedureQuotations := 0
+	// where each open list literal starts in tokens, innermost last
+	var listStarts []int
+	// how many list literals were open when the current procedure started
+	procedureLists := 0
+	// the open { and [ delimiters in order, so a quotation and a list cannot close across each other
+	var delimiters []string
+	// the if, while and scope blocks that are open, innermost last, so the end of a scope is known
+	var openBlocks []string
+	// the names declared in each open scope, innermost last
+	var scopeNames []map[string]bool
+
+	// declareVariable adds lastAddedVariable once its value is known. Outside of a scope it is
+	// declared while tokenizing, inside one it is declared when the program reaches it so it can
+	// be removed again at the end of the scope
+	declareVariable := func() {
+		if len(scopeNames) > 0 {
+			tokens = append(tokens, StackElement{Type: KeyWord, Value: Declaration{Variable: lastAddedVariable}})
+			return
+		}
+
+		variables[lastAddedVariable.Name] = lastAddedVariable
+		tokens = append(tokens, StackElement{Type: Identifier, Value: lastAddedVariable.Name})
+	}
+
 	// Current state
 	state := StateNormal
 	stateMachine := TokeniserStateMachine{}
@@ -213,7 +924,16 @@ func Tokenize(s string) ([]StackElement, map[string]Variable, error) {
 	stateMachine.States = map[int]Tokeniser{
 		StateNormal: {
 			HandleToken: func(s string) ([]StackElement, map[string]Variable, error) {
+				terminates := terminatesDeclaration
+				terminatesDeclaration = false
+
 				switch {
+				case hexRegex.MatchString(s) || binaryRegex.MatchString(s):
+					val, err := strconv.ParseInt(s, 0, 64)
+					if err != nil {
+						return nil, nil, fmt.Errorf("ERROR: invalid integer literal: %s", s)
+					}
+					tokens = append(tokens, StackElement{Type: Int, Value: int(val)})
 				case integerRegex.MatchString(s):
 					val, _ := strconv.Atoi(s)
 					tokens = append(tokens, StackElement{Type: Int, Value: val})
@@ -228,12 +948,113 @@ func Tokenize(s string) ([]StackElement, map[string]Variable, error) {
 					tokens = append(tokens, StackElement{Type: Bool, Value: val})
 				case operatorRegex.MatchString(s):
 					tokens = append(tokens, StackElement{Type: Operator, Value: operatorMap[s]})
+				case s == "{":
+					quotationStarts = append(quotationStarts, len(tokens))
+					delimiters = append(delimiters, s)
+				case s == "}":
+					if len(quotationStarts) < 1 || (inProcedure && len(quotationStarts) <= procedureQuotations) {
+						return nil, nil, errors.New("ERROR: } without a matching {")
+					}
+
+					if delimiters[len(delimiters)-1] == "[" {
+						return nil, nil, errors.New("ERROR: [ without a matching ]")
+					}
+
+					// the tokens since the matching { become the body of the quotation
+					start := quotationStarts[len(quotationStarts)-1]
+					quotationStarts = quotationStarts[:len(quotationStarts)-1]
+					delimiters = delimiters[:len(delimiters)-1]
+					body := append([]StackElement{}, tokens[start:]...)
+					tokens = append(tokens[:start], StackElement{Type: Quotation, Value: body})
+				case s == "[":
+					listStarts = append(listStarts, len(tokens))
+					delimiters = append(delimiters, s)
+				case s == "]":
+					if len(listStarts) < 1 || (inProcedure && len(listStarts) <= procedureLists) {
+						return nil, nil, errors.New("ERROR: ] without a matching [")
+					}
+
+					if delimiters[len(delimiters)-1] == "{" {
+						return nil, nil, errors.New("ERROR: { without a matching }")
+					}
+
+					// the values since the matching [ become the elements of the list
+					start := listStarts[len(listStarts)-1]
+					listStarts = listStarts[:len(listStarts)-1]
+					delimiters = delimiters[:len(delimiters)-1]
+					items := append([]StackElement{}, tokens[start:]...)
+					for _, item := range items {
+						switch item.Type {
+						case Int, Float, String, Bool, List, Quotation:
+						default:
+							return nil, nil, fmt.Errorf("ERROR: list literals can only contain values, but got %v", item)
+						}
+					}
+					tokens = append(tokens[:start], StackElement{Type: List, Value: items})
+				case blockRegex.MatchString(s):
+					if inProcedure {
+						switch {
+						case s == "if" || s == "while" || s == "scope":
+							procedureBlocks++
+						case s == "end" && procedureBlocks > 0:
+							procedureBlocks--
+						case s == "end":
+							if len(quotationStarts) > procedureQuotations {
+								return nil, nil, errors.New("ERROR: { without a matching }")
+							}
+
+							if len(listStarts) > procedureLists {
+								return nil, nil, errors.New("ERROR: [ without a matching ]")
+							}
+
+							// this end closes the procedure, so its body is replaced by the definition
+							body := append([]StackElement{}, tokens[procedureStart:]...)
+							tokens = append(tokens[:procedureStart], StackElement{Type: KeyWord, Value: Procedure{Name: procedureName, Body: body}})
+							inProcedure = false
+							return tokens, nil, nil
+						}
+					}
+
+					switch s {
+					case "if", "while", "scope":
+						openBlocks = append(openBlocks, s)
+						if s == "scope" {
+							scopeNames = append(scopeNames, make(map[string]bool))
+						}
+					case "end":
+						// an unmatched end is reported by ExecuteProgram
+						if len(openBlocks) > 0 {
+							if openBlocks[len(openBlocks)-1] == "scope" {
+								scopeNames = scopeNames[:len(scopeNames)-1]
+							}
+							openBlocks = openBlocks[:len(openBlocks)-1]
+						}
+					}
+
+					// block markers are kept as keywords so ExecuteProgram can jump between them
+					tokens = append(tokens, StackElement{Type: KeyWord, Value: s})
+				case keyWordRegex.MatchString(s) && s == "def" && !terminates:
+					// a def that does not end a variable declaration starts a procedure
+					if redeclared != "" && len(tokens) == redeclaredAt+1 {
+						return nil, nil, fmt.Errorf("variable %s has already been declared", redeclared)
+					}
+					if inProcedure {
+						return nil, nil, errors.New("ERROR: cannot define a procedure inside another procedure")
+					}
+					stateMachine.SetState(StateProcedureName)
 				case keyWordRegex.MatchString(s):
 					// Reset back to normal state since we've encountered the def keyword which means we're done declaring variables
 					if strings.TrimSpace(s) == "const" {
 						// variable is a constant
 						lastAddedVariable.Const = true
-						variables[lastAddedVariable.Name] = lastAddedVariable
+						if len(scopeNames) == 0 {
+							variables[lastAddedVariable.Name] = lastAddedVariable
+						} else if last := len(tokens) - 1; last >= 0 {
+							// inside a scope the declaration was the last token
+							if _, ok := tokens[last].Value.(Declaration); ok {
+								tokens[last].Value = Declaration{Variable: lastAddedVariable}
+							}
+						}
 					}
 				case operatorRegex.MatchString(s):
 					// means an operator comes after a variable, most likely we are reassiging a variable
@@ -258,14 +1079,21 @@ func Tokenize(s string) ([]StackElement, map[string]Variable, error) {
 				case varUsageRegex.MatchString(s):
 					// check if the variable exists
 					// if it does, add it's value to the tokens
-					variable, exists := variables[s[1:]]
+					name := s[1:]
+					_, exists := variables[name]
+					for _, names := range scopeNames {
+						exists = exists || names[name]
+					}
 
 					if !exists {
-						return nil, nil, fmt.Errorf("variable %s has not been declared", s[1:])
+						return nil, nil, wrapError(ErrUndeclaredVariable, "variable %s has not been declared", name)
 					}
 
 					// tokens = append(tokens, StackElement{Type: variable.Type, Value: variable.Value})
-					tokens = append(tokens, StackElement{Type: Identifier, Value: variable.Name})
+					tokens = append(tokens, StackElement{Type: Identifier, Value: name})
+				case procedures[s]:
+					// procedure calls are keywords holding the name of the procedure
+					tokens = append(tokens, StackElement{Type: KeyWord, Value: s})
 				default:
 					return nil, nil, fmt.Errorf("invalid token: %s", s)
 				}
@@ -280,36 +1108,40 @@ func Tokenize(s string) ([]StackElement, map[string]Variable, error) {
 				// check if the variable map is not empty
 				// if it is not empty, get the last token and add the value to the variable map
 				// if it is empty, return an error
-				if len(variables) > 0 {
+				if len(variables) > 0 || len(scopeNames) > 0 {
 					if operatorRegex.MatchString(part) {
 						// idk why this would happen
 						tokens = append(tokens, StackElement{Type: Operator, Value: operatorMap[part]})
 					} else {
 						switch {
+						case hexRegex.MatchString(part) || binaryRegex.MatchString(part):
+							val, err := strconv.ParseInt(part, 0, 64)
+							if err != nil {
+								return nil, nil, fmt.Errorf("ERROR: invalid integer literal: %s", part)
+							}
+							lastAddedVariable.Value = int(val)
+							lastAddedVariable.Type = Int
+							declareVariable()
 						case integerRegex.MatchString(part):
 							val, _ := strconv.Atoi(part)
 							lastAddedVariable.Value = val
 							lastAddedVariable.Type = Int
-							variables[lastAddedVariable.Name] = lastAddedVariable
-							tokens = append(tokens, StackElement{Type: Identifier, Value: lastAddedVariable.Name})
+							declareVariable()
 						case floatRegex.MatchString(part):
 							val, _ := strconv.ParseFloat(part, 64)
 							lastAddedVariable.Value = val
 							lastAddedVariable.Type = Float
-							variables[lastAddedVariable.Name] = lastAddedVariable
-							tokens = append(tokens, StackElement{Type: Identifier, Value: lastAddedVariable.Name})
+							declareVariable()
 						case stringRegex.MatchString(part):
 							value := strings.Trim(part, `"`)
 							lastAddedVariable.Value = value
 							lastAddedVariable.Type = String
-							variables[lastAddedVariable.Name] = lastAddedVariable
-							tokens = append(tokens, StackElement{Type: Identifier, Value: lastAddedVariable.Name})
+							declareVariable()
 						case boolRegex.MatchString(part):
 							val := part == "true"
 							lastAddedVariable.Value = val
 							lastAddedVariable.Type = Bool
-							variables[lastAddedVariable.Name] = lastAddedVariable
-							tokens = append(tokens, StackElement{Type: Identifier, Value: lastAddedVariable.Name})
+							declareVariable()
 						default:
 							return nil, nil, fmt.Errorf("invalid type: %s", part)
 						}
@@ -318,10 +1150,32 @@ func Tokenize(s string) ([]StackElement, map[string]Variable, error) {
 
 				// Reset the state to normal
 				stateMachine.SetState(StateNormal)
+				terminatesDeclaration = true
 
 				return nil, variables, nil
 			},
 		},
+		StateProcedureName: {
+			HandleToken: func(name string) ([]StackElement, map[string]Variable, error) {
+				stateMachine.SetState(StateNormal)
+
+				if !procedureNameRegex.MatchString(name) || operatorRegex.MatchString(name) ||
+					keyWordRegex.MatchString(name) || blockRegex.MatchString(name) || boolRegex.MatchString(name) {
+					return nil, nil, fmt.Errorf("ERROR: invalid procedure name: %s", name)
+				}
+
+				// the name is known before the body so a procedure can call itself
+				procedures[name] = true
+				procedureName = name
+				procedureStart = len(tokens)
+				procedureBlocks = 0
+				procedureQuotations = len(quotationStarts)
+				procedureLists = len(listStarts)
+				inProcedure = true
+
+				return nil, nil, nil
+			},
+		},
 	}
 
 	// Parse each token
@@ -333,21 +1187,29 @@ func Tokenize(s string) ([]StackElement, map[string]Variable, error) {
 
 		// set the machine state based on the current token
 		if varNameRegex.MatchString(part) {
-			// check if variable already exists in the map
-			_, exists := variables[part[1:]]
+			varName := part[1:] // Remove the leading '/'
 
-			if exists {
+			// a scope can shadow a variable declared outside of it, but a name can only be
+			// declared once in the same scope
+			if len(scopeNames) > 0 {
+				if scopeNames[len(scopeNames)-1][varName] {
+					return nil, nil, fmt.Errorf("variable %s has already been declared", varName)
+				}
+				scopeNames[len(scopeNames)-1][varName] = true
+			} else if _, exists := variables[varName]; exists {
 				// just jump because we've already declared the variable
-				// and we're probably just using it
+				// and we're probably just using it, unless a value and a def follow
+				redeclared = varName
+				redeclaredAt = len(tokens)
 				continue
 			} else {
-				stateMachine.SetState(StateVarDeclaration)
-				varName := part[1:] // Remove the leading '/'
 				variables[varName] = Variable{Name: varName, Type: Identifier}
-				lastAddedVariable = variables[varName]
-				continue
 			}
 
+			stateMachine.SetState(StateVarDeclaration)
+			lastAddedVariable = Variable{Name: varName, Type: Identifier}
+			continue
+
 		}
 
 		_, _, err := stateMachine.States[stateMachine.CurrentState].HandleToken(part)
@@ -357,26 +1219,54 @@ func Tokenize(s string) ([]StackElement, map[string]Variable, error) {
 		}
 	}
 
+	if stateMachine.CurrentState == StateProcedureName {
+		return nil, nil, errors.New("ERROR: def without a procedure name")
+	}
+
+	if len(quotationStarts) > 0 {
+		return nil, nil, errors.New("ERROR: { without a matching }")
+	}
+
+	if len(listStarts) > 0 {
+		return nil, nil, errors.New("ERROR: [ without a matching ]")
+	}
+
+	if inProcedure {
+		return nil, nil, fmt.Errorf("ERROR: procedure %s is missing its end", procedureName)
+	}
+
 	return tokens, variables, nil
 }
 
 func (g *Gorth) GPrint(val interface{}) {
 	if g.DebugMode {
-		fmt.Println(val)
+		fmt.Fprintln(g.Out, val)
 	}
 }
 
 func (g *Gorth) Push(val StackElement) error {
 	if len(g.ExecStack) >= g.MaxStackSize {
-		return errors.New("ERROR: stack overflow")
+		return wrapError(ErrStackOverflow, "ERROR: stack overflow")
 	}
 	g.ExecStack = append(g.ExecStack, val)
+	if len(g.ExecStack) > g.stackHighWater {
+		g.stackHighWater = len(g.ExecStack)
+	}
 	return nil
 }
 
+// pushFloat pushes the float result of op, rejecting NaN and ±Inf when RejectNonFinite is set
+func (g *Gorth) pushFloat(op string, val float64) error {
+	if g.RejectNonFinite && (math.IsNaN(val) || math.IsInf(val, 0)) {
+		return fmt.Errorf("ERROR: non-finite result in %v", op)
+	}
+
+	return g.Push(StackElement{Type: Float, Value: val})
+}
+
 func (g *Gorth) Pop() (StackElement, error) {
 	if len(g.ExecStack) < 1 {
-		return StackElement{}, errors.New("ERROR: cannot pop from an empty stack")
+		return StackElement{}, wrapError(ErrStackEmpty, "ERROR: cannot pop from an empty stack")
 	}
 	val := g.ExecStack[len(g.ExecStack)-1]
 	g.ExecStack = g.ExecStack[:len(g.ExecStack)-1]
@@ -385,13 +1275,13 @@ func (g *Gorth) Pop() (StackElement, error) {
 
 func (g *Gorth) Drop() error {
 	if len(g.ExecStack) < 1 {
-		return errors.New("ERROR: cannot drop from an empty stack")
+		return wrapError(ErrStackEmpty, "ERROR: cannot drop from an empty stack")
 	}
 
 	// if g is a variable, delete it from the variable map
 	// since it's no longer in use
 	if g.ExecStack[len(g.ExecStack)-1].Type == Identifier {
-		delete(g.VariableMap, g.ExecStack[len(g.ExecStack)-1].Value.(string))
+		g.deleteVariable(g.ExecStack[len(g.ExecStack)-1].Value.(string))
 	}
 
 	_, err := g.Pop()
@@ -407,18 +1297,54 @@ func (g *Gorth) Dump() error {
 
 	switch val.Type {
 	case Int, String, Bool:
-		fmt.Println(val.Value)
+		fmt.Fprintln(g.Out, val.Value)
 	case Identifier:
-		fmt.Println(g.VariableMap[val.Value.(string)].Value)
+		fmt.Fprintln(g.Out, g.variable(val.Value.(string)).Value)
+	default:
+		return wrapError(ErrTypeMismatch, "ERROR: top element is not a printable type")
+	}
+	return nil
+}
+
+func (g *Gorth) PopPrint() error {
+	// forth style ., pops the top value and prints it, resolving identifiers
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	switch val.Type {
+	case Int, String, Bool, Float:
+		fmt.Fprintln(g.Out, val.Value)
 	default:
-		return errors.New("ERROR: top element is not a printable type")
+		return wrapError(ErrTypeMismatch, "ERROR: top element is not a printable type")
+	}
+	return nil
+}
+
+func (g *Gorth) Emit() error {
+	// pops an int codepoint and prints its character, without a newline
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform EMIT_OP on non integer types")
+	}
+
+	n := val.Value.(int)
+	if n < 0 || n > utf8.MaxRune || !utf8.ValidRune(rune(n)) {
+		return fmt.Errorf("ERROR: %d is not a valid codepoint for EMIT_OP", n)
 	}
+
+	fmt.Fprint(g.Out, string(rune(n)))
 	return nil
 }
 
 func (g *Gorth) Rot() error {
 	if len(g.ExecStack) < 3 {
-		return errors.New("ERROR: at least 3 elements need to be on stack to perform ROT_OP")
+		return wrapError(ErrStackEmpty, "ERROR: at least 3 elements need to be on stack to perform ROT_OP")
 	}
 
 	val1, err := g.Pop()
@@ -454,9 +1380,48 @@ func (g *Gorth) Rot() error {
 	return nil
 }
 
+func (g *Gorth) RotBack() error {
+	// inverse of rot, moves the top element down to the third position
+	if len(g.ExecStack) < 3 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 3 elements need to be on stack to perform ROT_BACK_OP")
+	}
+
+	val1, err := g.Pop()
+	if err != nil {
+		return err
+	}
+
+	val2, err := g.Pop()
+	if err != nil {
+		return err
+	}
+
+	val3, err := g.Pop()
+	if err != nil {
+		return err
+	}
+
+	err = g.Push(val1)
+	if err != nil {
+		return err
+	}
+
+	err = g.Push(val3)
+	if err != nil {
+		return err
+	}
+
+	err = g.Push(val2)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (g *Gorth) Peek() (StackElement, error) {
 	if len(g.ExecStack) < 1 {
-		return StackElement{}, errors.New("ERROR: cannot PEEK_OP at an empty stack")
+		return StackElement{}, wrapError(ErrStackEmpty, "ERROR: cannot PEEK_OP at an empty stack")
 	}
 	return g.ExecStack[len(g.ExecStack)-1], nil
 }
@@ -469,21 +1434,21 @@ func (g *Gorth) Print() error {
 
 	switch val.Type {
 	case Int, String, Bool, Float:
-		fmt.Println(val.Value)
+		fmt.Fprintln(g.Out, val.Value)
 	case Identifier:
 		// we use value since we set the value of variables on the element stack to the name of the variable
-		_, exists := g.VariableMap[val.Value.(string)]
+		_, exists := g.lookupVariable(val.Value.(string))
 
 		if !exists {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val.Value.(string))
 		}
 
-		switch g.VariableMap[val.Value.(string)].Type {
+		switch g.variable(val.Value.(string)).Type {
 		case Int, String, Bool, Float:
-			fmt.Println(g.VariableMap[val.Value.(string)].Value)
+			fmt.Fprintln(g.Out, g.variable(val.Value.(string)).Value)
 		}
 	default:
-		return errors.New("ERROR: top element is not a printable type")
+		return wrapError(ErrTypeMismatch, "ERROR: top element is not a printable type")
 	}
 	return nil
 }
@@ -511,104 +1476,104 @@ func (g *Gorth) Add() error {
 	// float addition
 	case val1.Type == Float && val2.Type == Float:
 		sum := val1.Value.(float64) + val2.Value.(float64)
-		g.Push(StackElement{Type: Float, Value: sum})
+		return g.pushFloat("ADD_OP", sum)
 	// mixed type addition
 	case val1.Type == Int && val2.Type == Float:
 		sum := val2.Value.(float64) + float64(val1.Value.(int))
-		g.Push(StackElement{Type: Float, Value: sum})
+		return g.pushFloat("ADD_OP", sum)
 	case val1.Type == Float && val2.Type == Int:
 		sum := val1.Value.(float64) + float64(val2.Value.(int))
-		g.Push(StackElement{Type: Float, Value: sum})
+		return g.pushFloat("ADD_OP", sum)
 	// both variables
 	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Int:
-			sum := g.VariableMap[val1.Value.(string)].Value.(int) + g.VariableMap[val2.Value.(string)].Value.(int)
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Int:
+			sum := g.variable(val1.Value.(string)).Value.(int) + g.variable(val2.Value.(string)).Value.(int)
 			g.Push(StackElement{Type: Int, Value: sum})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Float:
-			sum := g.VariableMap[val1.Value.(string)].Value.(float64) + g.VariableMap[val2.Value.(string)].Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sum})
-		case g.VariableMap[val1.Value.(string)].Type == String && g.VariableMap[val2.Value.(string)].Type == String:
-			concat := g.VariableMap[val1.Value.(string)].Value.(string) + g.VariableMap[val2.Value.(string)].Value.(string)
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Float:
+			sum := g.variable(val1.Value.(string)).Value.(float64) + g.variable(val2.Value.(string)).Value.(float64)
+			return g.pushFloat("ADD_OP", sum)
+		case g.variable(val1.Value.(string)).Type == String && g.variable(val2.Value.(string)).Type == String:
+			concat := g.variable(val1.Value.(string)).Value.(string) + g.variable(val2.Value.(string)).Value.(string)
 			g.Push(StackElement{Type: String, Value: concat})
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Float:
-			sum := float64(g.VariableMap[val1.Value.(string)].Value.(int)) + g.VariableMap[val2.Value.(string)].Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sum})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Int:
-			sum := g.VariableMap[val1.Value.(string)].Value.(float64) + float64(g.VariableMap[val2.Value.(string)].Value.(int))
-			g.Push(StackElement{Type: Float, Value: sum})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Float:
+			sum := float64(g.variable(val1.Value.(string)).Value.(int)) + g.variable(val2.Value.(string)).Value.(float64)
+			return g.pushFloat("ADD_OP", sum)
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Int:
+			sum := g.variable(val1.Value.(string)).Value.(float64) + float64(g.variable(val2.Value.(string)).Value.(int))
+			return g.pushFloat("ADD_OP", sum)
 		default:
-			return errors.New("ERROR: cannot perform ADD_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform ADD_OP on different types")
 		}
 	// val1 is a variable and val2 is not
 	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Int:
-			sum := g.VariableMap[val1.Value.(string)].Value.(int) + val2.Value.(int)
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Int:
+			sum := g.variable(val1.Value.(string)).Value.(int) + val2.Value.(int)
 			g.Push(StackElement{Type: Int, Value: sum})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Float:
-			sum := g.VariableMap[val1.Value.(string)].Value.(float64) + val2.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sum})
-		case g.VariableMap[val1.Value.(string)].Type == String && val2.Type == String:
-			concat := g.VariableMap[val1.Value.(string)].Value.(string) + val2.Value.(string)
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Float:
+			sum := g.variable(val1.Value.(string)).Value.(float64) + val2.Value.(float64)
+			return g.pushFloat("ADD_OP", sum)
+		case g.variable(val1.Value.(string)).Type == String && val2.Type == String:
+			concat := g.variable(val1.Value.(string)).Value.(string) + val2.Value.(string)
 			g.Push(StackElement{Type: String, Value: concat})
 		// one is an int and the other is a float
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Float:
-			sum := float64(g.VariableMap[val1.Value.(string)].Value.(int)) + val2.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sum})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Int:
-			sum := g.VariableMap[val1.Value.(string)].Value.(float64) + float64(val2.Value.(int))
-			g.Push(StackElement{Type: Float, Value: sum})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Float:
+			sum := float64(g.variable(val1.Value.(string)).Value.(int)) + val2.Value.(float64)
+			return g.pushFloat("ADD_OP", sum)
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Int:
+			sum := g.variable(val1.Value.(string)).Value.(float64) + float64(val2.Value.(int))
+			return g.pushFloat("ADD_OP", sum)
 		default:
-			return errors.New("ERROR: cannot perform ADD_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform ADD_OP on different types")
 		}
 	// val2 is a variable and val1 is not
 	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Int:
-			sum := g.VariableMap[val2.Value.(string)].Value.(int) + val1.Value.(int)
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Int:
+			sum := g.variable(val2.Value.(string)).Value.(int) + val1.Value.(int)
 			g.Push(StackElement{Type: Int, Value: sum})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Float:
-			sum := g.VariableMap[val2.Value.(string)].Value.(float64) + val1.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sum})
-		case g.VariableMap[val2.Value.(string)].Type == String && val1.Type == String:
-			concat := g.VariableMap[val2.Value.(string)].Value.(string) + val1.Value.(string)
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Float:
+			sum := g.variable(val2.Value.(string)).Value.(float64) + val1.Value.(float64)
+			return g.pushFloat("ADD_OP", sum)
+		case g.variable(val2.Value.(string)).Type == String && val1.Type == String:
+			concat := g.variable(val2.Value.(string)).Value.(string) + val1.Value.(string)
 			g.Push(StackElement{Type: String, Value: concat})
 		// one is an int and the other is a float
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Float:
-			sum := float64(g.VariableMap[val2.Value.(string)].Value.(int)) + val1.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sum})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Int:
-			sum := g.VariableMap[val2.Value.(string)].Value.(float64) + float64(val1.Value.(int))
-			g.Push(StackElement{Type: Float, Value: sum})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Float:
+			sum := float64(g.variable(val2.Value.(string)).Value.(int)) + val1.Value.(float64)
+			return g.pushFloat("ADD_OP", sum)
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Int:
+			sum := g.variable(val2.Value.(string)).Value.(float64) + float64(val1.Value.(int))
+			return g.pushFloat("ADD_OP", sum)
 		default:
-			return errors.New("ERROR: cannot perform ADD_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform ADD_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform ADD_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform ADD_OP on different types")
 	}
 	return nil
 }
@@ -630,95 +1595,95 @@ func (g *Gorth) Sub() error {
 	// float subtraction
 	case val1.Type == Float && val2.Type == Float:
 		sub := val2.Value.(float64) - val1.Value.(float64)
-		g.Push(StackElement{Type: Float, Value: sub})
+		return g.pushFloat("SUB_OP", sub)
 	// mixed number subtraction
 	case val1.Type == Int && val2.Type == Float:
 		sub := val2.Value.(float64) - float64(val1.Value.(int))
-		g.Push(StackElement{Type: Float, Value: sub})
+		return g.pushFloat("SUB_OP", sub)
 	case val1.Type == Float && val2.Type == Int:
 		sub := float64(val2.Value.(int)) - val1.Value.(float64)
-		g.Push(StackElement{Type: Float, Value: sub})
+		return g.pushFloat("SUB_OP", sub)
 	// variable subtraction
 	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Int:
-			sub := g.VariableMap[val2.Value.(string)].Value.(int) - g.VariableMap[val1.Value.(string)].Value.(int)
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Int:
+			sub := g.variable(val2.Value.(string)).Value.(int) - g.variable(val1.Value.(string)).Value.(int)
 			g.Push(StackElement{Type: Int, Value: sub})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Float:
-			sub := g.VariableMap[val2.Value.(string)].Value.(float64) - g.VariableMap[val1.Value.(string)].Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sub})
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Float:
-			sub := g.VariableMap[val2.Value.(string)].Value.(float64) - float64(g.VariableMap[val1.Value.(string)].Value.(int))
-			g.Push(StackElement{Type: Float, Value: sub})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Int:
-			sub := float64(g.VariableMap[val2.Value.(string)].Value.(int)) - g.VariableMap[val1.Value.(string)].Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sub})
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Float:
+			sub := g.variable(val2.Value.(string)).Value.(float64) - g.variable(val1.Value.(string)).Value.(float64)
+			return g.pushFloat("SUB_OP", sub)
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Float:
+			sub := g.variable(val2.Value.(string)).Value.(float64) - float64(g.variable(val1.Value.(string)).Value.(int))
+			return g.pushFloat("SUB_OP", sub)
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Int:
+			sub := float64(g.variable(val2.Value.(string)).Value.(int)) - g.variable(val1.Value.(string)).Value.(float64)
+			return g.pushFloat("SUB_OP", sub)
 		default:
-			return errors.New("ERROR: cannot perform SUB_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform SUB_OP on different types")
 		}
 	// val1 is a variable and val2 is not
 	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Int:
-			sub := val2.Value.(int) - g.VariableMap[val1.Value.(string)].Value.(int)
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Int:
+			sub := val2.Value.(int) - g.variable(val1.Value.(string)).Value.(int)
 			g.Push(StackElement{Type: Int, Value: sub})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Float:
-			sub := val2.Value.(float64) - g.VariableMap[val1.Value.(string)].Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sub})
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Float:
+			sub := val2.Value.(float64) - g.variable(val1.Value.(string)).Value.(float64)
+			return g.pushFloat("SUB_OP", sub)
 		// one is an int and the other is a float
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Float:
-			sub := val2.Value.(float64) - float64(g.VariableMap[val1.Value.(string)].Value.(int))
-			g.Push(StackElement{Type: Float, Value: sub})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Int:
-			sub := float64(val2.Value.(int)) - g.VariableMap[val1.Value.(string)].Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sub})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Float:
+			sub := val2.Value.(float64) - float64(g.variable(val1.Value.(string)).Value.(int))
+			return g.pushFloat("SUB_OP", sub)
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Int:
+			sub := float64(val2.Value.(int)) - g.variable(val1.Value.(string)).Value.(float64)
+			return g.pushFloat("SUB_OP", sub)
 		default:
-			return errors.New("ERROR: cannot perform SUB_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform SUB_OP on different types")
 		}
 	// val2 is a variable and val1 is not
 	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Int:
-			sub := g.VariableMap[val2.Value.(string)].Value.(int) - val1.Value.(int)
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Int:
+			sub := g.variable(val2.Value.(string)).Value.(int) - val1.Value.(int)
 			g.Push(StackElement{Type: Int, Value: sub})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Float:
-			sub := g.VariableMap[val2.Value.(string)].Value.(float64) - val1.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sub})
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Float:
+			sub := g.variable(val2.Value.(string)).Value.(float64) - val1.Value.(float64)
+			return g.pushFloat("SUB_OP", sub)
 		// one is an int and the other is a float
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Float:
-			sub := float64(g.VariableMap[val2.Value.(string)].Value.(int)) - val1.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: sub})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Int:
-			sub := g.VariableMap[val2.Value.(string)].Value.(float64) - float64(val1.Value.(int))
-			g.Push(StackElement{Type: Float, Value: sub})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Float:
+			sub := float64(g.variable(val2.Value.(string)).Value.(int)) - val1.Value.(float64)
+			return g.pushFloat("SUB_OP", sub)
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Int:
+			sub := g.variable(val2.Value.(string)).Value.(float64) - float64(val1.Value.(int))
+			return g.pushFloat("SUB_OP", sub)
 		default:
-			return errors.New("ERROR: cannot perform SUB_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform SUB_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform SUB_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform SUB_OP on different types")
 	}
 	return nil
 }
@@ -760,153 +1725,155 @@ func (g *Gorth) Mul() error {
 	// float multiplication
 	case val1.Type == Float && val2.Type == Float:
 		mul := val1.Value.(float64) * val2.Value.(float64)
-		g.Push(StackElement{Type: Float, Value: mul})
+		return g.pushFloat("MUL_OP", mul)
 	// one is float and the other is an int
 	case val1.Type == Int && val2.Type == Float:
 		mul := float64(val1.Value.(int)) * val2.Value.(float64)
-		g.Push(StackElement{Type: Float, Value: mul})
+		return g.pushFloat("MUL_OP", mul)
 	case val1.Type == Float && val2.Type == Int:
 		mul := val1.Value.(float64) * float64(val2.Value.(int))
-		g.Push(StackElement{Type: Float, Value: mul})
+		return g.pushFloat("MUL_OP", mul)
 	// variable multiplication
 	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Int:
-			mul := g.VariableMap[val1.Value.(string)].Value.(int) * g.VariableMap[val2.Value.(string)].Value.(int)
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Int:
+			mul := g.variable(val1.Value.(string)).Value.(int) * g.variable(val2.Value.(string)).Value.(int)
 			g.Push(StackElement{Type: Int, Value: mul})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Float:
-			mul := g.VariableMap[val1.Value.(string)].Value.(float64) * g.VariableMap[val2.Value.(string)].Value.(float64)
-			g.Push(StackElement{Type: Float, Value: mul})
-		case g.VariableMap[val1.Value.(string)].Type == String && g.VariableMap[val2.Value.(string)].Type == Int:
-			str := g.VariableMap[val1.Value.(string)].Value.(string)
-			num := g.VariableMap[val2.Value.(string)].Value.(int)
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Float:
+			mul := g.variable(val1.Value.(string)).Value.(float64) * g.variable(val2.Value.(string)).Value.(float64)
+			return g.pushFloat("MUL_OP", mul)
+		case g.variable(val1.Value.(string)).Type == String && g.variable(val2.Value.(string)).Type == Int:
+			str := g.variable(val1.Value.(string)).Value.(string)
+			num := g.variable(val2.Value.(string)).Value.(int)
 			var concat string
 			for i := 0; i < num; i++ {
 				concat += str
 			}
 			g.Push(StackElement{Type: String, Value: concat})
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == String:
-			str := g.VariableMap[val2.Value.(string)].Value.(string)
-			num := g.VariableMap[val1.Value.(string)].Value.(int)
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == String:
+			str := g.variable(val2.Value.(string)).Value.(string)
+			num := g.variable(val1.Value.(string)).Value.(int)
 			var concat string
 			for i := 0; i < num; i++ {
 				concat += str
 			}
 			g.Push(StackElement{Type: String, Value: concat})
 		// one is a float and one is an int
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Float:
-			mul := float64(g.VariableMap[val1.Value.(string)].Value.(int)) * g.VariableMap[val2.Value.(string)].Value.(float64)
-			g.Push(StackElement{Type: Float, Value: mul})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Int:
-			mul := g.VariableMap[val1.Value.(string)].Value.(float64) * float64(g.VariableMap[val2.Value.(string)].Value.(int))
-			g.Push(StackElement{Type: Float, Value: mul})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Float:
+			mul := float64(g.variable(val1.Value.(string)).Value.(int)) * g.variable(val2.Value.(string)).Value.(float64)
+			return g.pushFloat("MUL_OP", mul)
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Int:
+			mul := g.variable(val1.Value.(string)).Value.(float64) * float64(g.variable(val2.Value.(string)).Value.(int))
+			return g.pushFloat("MUL_OP", mul)
 		default:
-			return errors.New("ERROR: cannot perform MUL_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform MUL_OP on different types")
 		}
 	// val1 is a variable and val2 is not
 	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Int:
-			mul := g.VariableMap[val1.Value.(string)].Value.(int) * val2.Value.(int)
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Int:
+			mul := g.variable(val1.Value.(string)).Value.(int) * val2.Value.(int)
 			g.Push(StackElement{Type: Int, Value: mul})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Float:
-			mul := g.VariableMap[val1.Value.(string)].Value.(float64) * val2.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: mul})
-		case g.VariableMap[val1.Value.(string)].Type == String && val2.Type == Int:
-			str := g.VariableMap[val1.Value.(string)].Value.(string)
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Float:
+			mul := g.variable(val1.Value.(string)).Value.(float64) * val2.Value.(float64)
+			return g.pushFloat("MUL_OP", mul)
+		case g.variable(val1.Value.(string)).Type == String && val2.Type == Int:
+			str := g.variable(val1.Value.(string)).Value.(string)
 			num := val2.Value.(int)
 			var concat string
 			for i := 0; i < num; i++ {
 				concat += str
 			}
 			g.Push(StackElement{Type: String, Value: concat})
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == String:
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == String:
 			str := val2.Value.(string)
-			num := g.VariableMap[val1.Value.(string)].Value.(int)
+			num := g.variable(val1.Value.(string)).Value.(int)
 			var concat string
 			for i := 0; i < num; i++ {
 				concat += str
 			}
 			g.Push(StackElement{Type: String, Value: concat})
 		// one is a float and one is an int
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Float:
-			mul := float64(g.VariableMap[val1.Value.(string)].Value.(int)) * val2.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: mul})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Int:
-			mul := g.VariableMap[val1.Value.(string)].Value.(float64) * float64(val2.Value.(int))
-			g.Push(StackElement{Type: Float, Value: mul})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Float:
+			mul := float64(g.variable(val1.Value.(string)).Value.(int)) * val2.Value.(float64)
+			return g.pushFloat("MUL_OP", mul)
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Int:
+			mul := g.variable(val1.Value.(string)).Value.(float64) * float64(val2.Value.(int))
+			return g.pushFloat("MUL_OP", mul)
 		default:
-			return errors.New("ERROR: cannot perform MUL_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform MUL_OP on different types")
 		}
 	// val2 is a variable and val1 is not
 	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Int:
-			mul := g.VariableMap[val2.Value.(string)].Value.(int) * val1.Value.(int)
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Int:
+			mul := g.variable(val2.Value.(string)).Value.(int) * val1.Value.(int)
 			g.Push(StackElement{Type: Int, Value: mul})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Float:
-			mul := g.VariableMap[val2.Value.(string)].Value.(float64) * val1.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: mul})
-		case g.VariableMap[val2.Value.(string)].Type == String && val1.Type == Int:
-			str := g.VariableMap[val2.Value.(string)].Value.(string)
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Float:
+			mul := g.variable(val2.Value.(string)).Value.(float64) * val1.Value.(float64)
+			return g.pushFloat("MUL_OP", mul)
+		case g.variable(val2.Value.(string)).Type == String && val1.Type == Int:
+			str := g.variable(val2.Value.(string)).Value.(string)
 			num := val1.Value.(int)
 			var concat string
 			for i := 0; i < num; i++ {
 				concat += str
 			}
 			g.Push(StackElement{Type: String, Value: concat})
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == String:
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == String:
 			str := val1.Value.(string)
-			num := g.VariableMap[val2.Value.(string)].Value.(int)
+			num := g.variable(val2.Value.(string)).Value.(int)
 			var concat string
 			for i := 0; i < num; i++ {
 				concat += str
 			}
 			g.Push(StackElement{Type: String, Value: concat})
 		// one is a float and one is an int
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Float:
-			mul := float64(g.VariableMap[val2.Value.(string)].Value.(int)) * val1.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: mul})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Int:
-			mul := g.VariableMap[val2.Value.(string)].Value.(float64) * float64(val1.Value.(int))
-			g.Push(StackElement{Type: Float, Value: mul})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Float:
+			mul := float64(g.variable(val2.Value.(string)).Value.(int)) * val1.Value.(float64)
+			return g.pushFloat("MUL_OP", mul)
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Int:
+			mul := g.variable(val2.Value.(string)).Value.(float64) * float64(val1.Value.(int))
+			return g.pushFloat("MUL_OP", mul)
 		default:
-			return errors.New("ERROR: cannot perform MUL_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform MUL_OP on different types")
 		}
 	// mixed type multiplication
 	case (val1.Type == Int && val2.Type == Float) || (val1.Type == Float && val2.Type == Int):
 		mul := val2.Value.(float64) * float64(val1.Value.(int))
-		g.Push(StackElement{Type: Float, Value: mul})
+		return g.pushFloat("MUL_OP", mul)
 	default:
-		return errors.New("ERROR: cannot perform MUL_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform MUL_OP on different types")
 	}
 	return nil
 }
 
 func (g *Gorth) Div() error {
+	// dividing by zero is an error for both ints and floats,
+	// floats do not silently produce +Inf, -Inf or NaN
 	val1, err := g.Pop()
 	if err != nil {
 		return err
@@ -918,110 +1885,148 @@ func (g *Gorth) Div() error {
 	switch {
 	// integer division
 	case val1.Type == Int && val2.Type == Int:
+		if val1.Value.(int) == 0 {
+			return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+		}
 		div := val2.Value.(int) / val1.Value.(int)
 		g.Push(StackElement{Type: Int, Value: div})
 	// float division
 	case val1.Type == Float && val2.Type == Float:
+		if val1.Value.(float64) == 0 {
+			return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+		}
 		div := val2.Value.(float64) / val1.Value.(float64)
-		g.Push(StackElement{Type: Float, Value: div})
+		return g.pushFloat("DIV_OP", div)
 	// one is float and the other is an int
 	case val1.Type == Int && val2.Type == Float:
+		if val1.Value.(int) == 0 {
+			return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+		}
 		div := val2.Value.(float64) / float64(val1.Value.(int))
-		g.Push(StackElement{Type: Float, Value: div})
+		return g.pushFloat("DIV_OP", div)
 	case val1.Type == Float && val2.Type == Int:
+		if val1.Value.(float64) == 0 {
+			return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+		}
 		div := float64(val2.Value.(int)) / val1.Value.(float64)
-		g.Push(StackElement{Type: Float, Value: div})
+		return g.pushFloat("DIV_OP", div)
 	// variables
 	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Int:
-			div := g.VariableMap[val2.Value.(string)].Value.(int) / g.VariableMap[val1.Value.(string)].Value.(int)
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Int:
+			if g.variable(val1.Value.(string)).Value.(int) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := g.variable(val2.Value.(string)).Value.(int) / g.variable(val1.Value.(string)).Value.(int)
 			g.Push(StackElement{Type: Int, Value: div})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Float:
-			div := g.VariableMap[val2.Value.(string)].Value.(float64) / g.VariableMap[val1.Value.(string)].Value.(float64)
-			g.Push(StackElement{Type: Float, Value: div})
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Float:
+			if g.variable(val1.Value.(string)).Value.(float64) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := g.variable(val2.Value.(string)).Value.(float64) / g.variable(val1.Value.(string)).Value.(float64)
+			return g.pushFloat("DIV_OP", div)
 		// one is an int and the other is a float
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Float:
-			div := g.VariableMap[val2.Value.(string)].Value.(float64) / float64(g.VariableMap[val1.Value.(string)].Value.(int))
-			g.Push(StackElement{Type: Float, Value: div})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Int:
-			div := float64(g.VariableMap[val2.Value.(string)].Value.(int)) / g.VariableMap[val1.Value.(string)].Value.(float64)
-			g.Push(StackElement{Type: Float, Value: div})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Float:
+			if g.variable(val1.Value.(string)).Value.(int) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := g.variable(val2.Value.(string)).Value.(float64) / float64(g.variable(val1.Value.(string)).Value.(int))
+			return g.pushFloat("DIV_OP", div)
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Int:
+			if g.variable(val1.Value.(string)).Value.(float64) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := float64(g.variable(val2.Value.(string)).Value.(int)) / g.variable(val1.Value.(string)).Value.(float64)
+			return g.pushFloat("DIV_OP", div)
 		default:
-			return errors.New("ERROR: cannot perform DIV_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform DIV_OP on different types")
 		}
 	// val1 is a variable and val2 is not
 	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
-		}
-
-		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Int:
-			div := val2.Value.(int) / g.VariableMap[val1.Value.(string)].Value.(int)
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Int:
+			if g.variable(val1.Value.(string)).Value.(int) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := val2.Value.(int) / g.variable(val1.Value.(string)).Value.(int)
 			g.Push(StackElement{Type: Int, Value: div})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Float:
-			div := val2.Value.(float64) / g.VariableMap[val1.Value.(string)].Value.(float64)
-			g.Push(StackElement{Type: Float, Value: div})
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Float:
+			if g.variable(val1.Value.(string)).Value.(float64) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := val2.Value.(float64) / g.variable(val1.Value.(string)).Value.(float64)
+			return g.pushFloat("DIV_OP", div)
 		// one is an int and the other is a float
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Float:
-			div := val2.Value.(float64) / float64(g.VariableMap[val1.Value.(string)].Value.(int))
-			g.Push(StackElement{Type: Float, Value: div})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Int:
-			div := g.VariableMap[val1.Value.(string)].Value.(float64) / float64(val2.Value.(int))
-			g.Push(StackElement{Type: Float, Value: div})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Float:
+			if g.variable(val1.Value.(string)).Value.(int) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := val2.Value.(float64) / float64(g.variable(val1.Value.(string)).Value.(int))
+			return g.pushFloat("DIV_OP", div)
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Int:
+			if g.variable(val1.Value.(string)).Value.(float64) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := float64(val2.Value.(int)) / g.variable(val1.Value.(string)).Value.(float64)
+			return g.pushFloat("DIV_OP", div)
 		default:
-			return errors.New("ERROR: cannot perform DIV_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform DIV_OP on different types")
 		}
 	// val2 is a variable and val1 is not
 	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
-
-		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
-		}
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Int:
-			div := g.VariableMap[val2.Value.(string)].Value.(int) / val1.Value.(int)
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Int:
+			if val1.Value.(int) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := g.variable(val2.Value.(string)).Value.(int) / val1.Value.(int)
 			g.Push(StackElement{Type: Int, Value: div})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Float:
-			div := g.VariableMap[val2.Value.(string)].Value.(float64) / val1.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: div})
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Float:
-			div := float64(g.VariableMap[val2.Value.(string)].Value.(int)) / val1.Value.(float64)
-			g.Push(StackElement{Type: Float, Value: div})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Int:
-			div := g.VariableMap[val2.Value.(string)].Value.(float64) / float64(val1.Value.(int))
-			g.Push(StackElement{Type: Float, Value: div})
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Float:
+			if val1.Value.(float64) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := g.variable(val2.Value.(string)).Value.(float64) / val1.Value.(float64)
+			return g.pushFloat("DIV_OP", div)
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Float:
+			if val1.Value.(float64) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := float64(g.variable(val2.Value.(string)).Value.(int)) / val1.Value.(float64)
+			return g.pushFloat("DIV_OP", div)
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Int:
+			if val1.Value.(int) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
+			}
+			div := g.variable(val2.Value.(string)).Value.(float64) / float64(val1.Value.(int))
+			return g.pushFloat("DIV_OP", div)
 		default:
-			return errors.New("ERROR: cannot perform DIV_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform DIV_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform DIV_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform DIV_OP on different types")
 	}
 	return nil
 }
@@ -1039,57 +2044,67 @@ func (g *Gorth) Mod() error {
 	// integer modulo
 	case val1.Type == Int && val2.Type == Int:
 		if val1.Value.(int) == 0 {
-			return errors.New("ERROR: cannot divide by zero")
+			return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
 		}
 		mod := val2.Value.(int) % val1.Value.(int)
 		g.Push(StackElement{Type: Int, Value: mod})
 	// variables
 	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Int:
-			if g.VariableMap[val1.Value.(string)].Value.(int) == 0 {
-				return errors.New("ERROR: cannot divide by zero")
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Int:
+			if g.variable(val1.Value.(string)).Value.(int) == 0 {
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
 			}
-			mod := g.VariableMap[val2.Value.(string)].Value.(int) % g.VariableMap[val1.Value.(string)].Value.(int)
+			mod := g.variable(val2.Value.(string)).Value.(int) % g.variable(val1.Value.(string)).Value.(int)
 			g.Push(StackElement{Type: Int, Value: mod})
 		default:
-			return errors.New("ERROR: cannot perform MOD_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform MOD_OP on different types")
 		}
 	// one is a variable and the other is not
 	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Int:
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Int:
 			if val1.Value.(int) == 0 {
-				return errors.New("ERROR: cannot divide by zero")
+				return wrapError(ErrDivideByZero, "ERROR: cannot divide by zero")
 			}
-			mod := val2.Value.(int) % g.VariableMap[val1.Value.(string)].Value.(int)
+			mod := val2.Value.(int) % g.variable(val1.Value.(string)).Value.(int)
 			g.Push(StackElement{Type: Int, Value: mod})
 		default:
-			return errors.New("ERROR: cannot perform MOD_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform MOD_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform MOD_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform MOD_OP on different types")
 	}
 	return nil
 }
 
+// pushIntPow pushes base to the power of an integer exponent, a negative exponent gives a Float
+// since the result is a fraction, eg. 2 -3 ^ is 0.125
+func (g *Gorth) pushIntPow(base, exponent int) error {
+	if exponent < 0 {
+		return g.pushFloat("EXP_OP", math.Pow(float64(base), float64(exponent)))
+	}
+
+	return g.Push(StackElement{Type: Int, Value: int(math.Pow(float64(base), float64(exponent)))})
+}
+
 func (g *Gorth) Exp() error {
 	val1, err := g.Pop()
 	if err != nil {
@@ -1102,103 +2117,98 @@ func (g *Gorth) Exp() error {
 	switch {
 	// integer exponentiation
 	case val1.Type == Int && val2.Type == Int:
-		exp := int(math.Pow(float64(val2.Value.(int)), float64(val1.Value.(int))))
-		g.Push(StackElement{Type: Int, Value: exp})
+		return g.pushIntPow(val2.Value.(int), val1.Value.(int))
 	// float exponentiation
 	case val1.Type == Float && val2.Type == Float:
 		exp := math.Pow(val2.Value.(float64), val1.Value.(float64))
-		g.Push(StackElement{Type: Float, Value: exp})
+		return g.pushFloat("EXP_OP", exp)
 	// mixed type exponentiation
 	case val1.Type == Int && val2.Type == Float:
 		exp := math.Pow(val2.Value.(float64), float64(val1.Value.(int)))
-		g.Push(StackElement{Type: Float, Value: exp})
+		return g.pushFloat("EXP_OP", exp)
 	case val1.Type == Float && val2.Type == Int:
 		exp := math.Pow(float64(val2.Value.(int)), val1.Value.(float64))
-		g.Push(StackElement{Type: Float, Value: exp})
+		return g.pushFloat("EXP_OP", exp)
 	// variables
 	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Int:
-			exp := int(math.Pow(float64(g.VariableMap[val2.Value.(string)].Value.(int)), float64(g.VariableMap[val1.Value.(string)].Value.(int))))
-			g.Push(StackElement{Type: Int, Value: exp})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Float:
-			exp := math.Pow(g.VariableMap[val2.Value.(string)].Value.(float64), g.VariableMap[val1.Value.(string)].Value.(float64))
-			g.Push(StackElement{Type: Float, Value: exp})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Int:
+			return g.pushIntPow(g.variable(val2.Value.(string)).Value.(int), g.variable(val1.Value.(string)).Value.(int))
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Float:
+			exp := math.Pow(g.variable(val2.Value.(string)).Value.(float64), g.variable(val1.Value.(string)).Value.(float64))
+			return g.pushFloat("EXP_OP", exp)
 		// one is an int and the other is a float
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Float:
-			exp := math.Pow(float64(g.VariableMap[val2.Value.(string)].Value.(int)), g.VariableMap[val1.Value.(string)].Value.(float64))
-			g.Push(StackElement{Type: Float, Value: exp})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Int:
-			exp := math.Pow(g.VariableMap[val2.Value.(string)].Value.(float64), float64(g.VariableMap[val1.Value.(string)].Value.(int)))
-			g.Push(StackElement{Type: Float, Value: exp})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Float:
+			exp := math.Pow(float64(g.variable(val2.Value.(string)).Value.(int)), g.variable(val1.Value.(string)).Value.(float64))
+			return g.pushFloat("EXP_OP", exp)
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Int:
+			exp := math.Pow(g.variable(val2.Value.(string)).Value.(float64), float64(g.variable(val1.Value.(string)).Value.(int)))
+			return g.pushFloat("EXP_OP", exp)
 		default:
-			return errors.New("ERROR: cannot perform EXP_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform EXP_OP on different types")
 		}
 	// val1 is a variable and val2 is not
 	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Int:
-			exp := int(math.Pow(float64(val2.Value.(int)), float64(g.VariableMap[val1.Value.(string)].Value.(int))))
-			g.Push(StackElement{Type: Int, Value: exp})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Float:
-			exp := math.Pow(val2.Value.(float64), g.VariableMap[val1.Value.(string)].Value.(float64))
-			g.Push(StackElement{Type: Float, Value: exp})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Int:
+			return g.pushIntPow(val2.Value.(int), g.variable(val1.Value.(string)).Value.(int))
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Float:
+			exp := math.Pow(val2.Value.(float64), g.variable(val1.Value.(string)).Value.(float64))
+			return g.pushFloat("EXP_OP", exp)
 		// one is an int and the other is a float
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Float:
-			exp := math.Pow(float64(val2.Value.(int)), g.VariableMap[val1.Value.(string)].Value.(float64))
-			g.Push(StackElement{Type: Float, Value: exp})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Int:
-			exp := math.Pow(val2.Value.(float64), float64(g.VariableMap[val1.Value.(string)].Value.(int)))
-			g.Push(StackElement{Type: Float, Value: exp})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Float:
+			exp := math.Pow(float64(val2.Value.(int)), g.variable(val1.Value.(string)).Value.(float64))
+			return g.pushFloat("EXP_OP", exp)
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Int:
+			exp := math.Pow(val2.Value.(float64), float64(g.variable(val1.Value.(string)).Value.(int)))
+			return g.pushFloat("EXP_OP", exp)
 		default:
-			return errors.New("ERROR: cannot perform EXP_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform EXP_OP on different types")
 		}
 	// val2 is a variable and val1 is not
 	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Int:
-			exp := int(math.Pow(float64(g.VariableMap[val2.Value.(string)].Value.(int)), float64(val1.Value.(int))))
-			g.Push(StackElement{Type: Int, Value: exp})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Float:
-			exp := math.Pow(g.VariableMap[val2.Value.(string)].Value.(float64), val1.Value.(float64))
-			g.Push(StackElement{Type: Float, Value: exp})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Int:
+			return g.pushIntPow(g.variable(val2.Value.(string)).Value.(int), val1.Value.(int))
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Float:
+			exp := math.Pow(g.variable(val2.Value.(string)).Value.(float64), val1.Value.(float64))
+			return g.pushFloat("EXP_OP", exp)
 		// one is an int and the other is a float
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Float:
-			exp := math.Pow(float64(g.VariableMap[val2.Value.(string)].Value.(int)), val1.Value.(float64))
-			g.Push(StackElement{Type: Float, Value: exp})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Int:
-			exp := math.Pow(g.VariableMap[val2.Value.(string)].Value.(float64), float64(val1.Value.(int)))
-			g.Push(StackElement{Type: Float, Value: exp})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Float:
+			exp := math.Pow(float64(g.variable(val2.Value.(string)).Value.(int)), val1.Value.(float64))
+			return g.pushFloat("EXP_OP", exp)
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Int:
+			exp := math.Pow(g.variable(val2.Value.(string)).Value.(float64), float64(val1.Value.(int)))
+			return g.pushFloat("EXP_OP", exp)
 		default:
-			return errors.New("ERROR: cannot perform EXP_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform EXP_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform EXP_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform EXP_OP on different types")
 	}
-	return nil
 }
 
 func (g *Gorth) Inc() error {
@@ -1213,28 +2223,28 @@ func (g *Gorth) Inc() error {
 		g.Push(StackElement{Type: Float, Value: val.Value.(float64) + 1})
 	// variable increment
 	case val.Type == Identifier:
-		_, exists := g.VariableMap[val.Value.(string)]
+		_, exists := g.lookupVariable(val.Value.(string))
 
 		if !exists {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val.Value.(string)].Type == Int:
-			incVal := g.VariableMap[val.Value.(string)].Value.(int) + 1
-			temp := g.VariableMap[val.Value.(string)]
+		case g.variable(val.Value.(string)).Type == Int:
+			incVal := g.variable(val.Value.(string)).Value.(int) + 1
+			temp := g.variable(val.Value.(string))
 			temp.Value = incVal
-			g.VariableMap[val.Value.(string)] = temp
-		case g.VariableMap[val.Value.(string)].Type == Float:
-			incVal := g.VariableMap[val.Value.(string)].Value.(float64) + 1
-			temp := g.VariableMap[val.Value.(string)]
+			g.setVariable(val.Value.(string), temp)
+		case g.variable(val.Value.(string)).Type == Float:
+			incVal := g.variable(val.Value.(string)).Value.(float64) + 1
+			temp := g.variable(val.Value.(string))
 			temp.Value = incVal
-			g.VariableMap[val.Value.(string)] = temp
+			g.setVariable(val.Value.(string), temp)
 		default:
-			return errors.New("ERROR: cannot perform INC_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform INC_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform INC_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform INC_OP on different types")
 	}
 	return nil
 }
@@ -1251,28 +2261,28 @@ func (g *Gorth) Dec() error {
 		g.Push(StackElement{Type: Float, Value: val.Value.(float64) - 1})
 	// variable decrement
 	case val.Type == Identifier:
-		_, exists := g.VariableMap[val.Value.(string)]
+		_, exists := g.lookupVariable(val.Value.(string))
 
 		if !exists {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val.Value.(string)].Type == Int:
-			decVal := g.VariableMap[val.Value.(string)].Value.(int) - 1
-			temp := g.VariableMap[val.Value.(string)]
+		case g.variable(val.Value.(string)).Type == Int:
+			decVal := g.variable(val.Value.(string)).Value.(int) - 1
+			temp := g.variable(val.Value.(string))
 			temp.Value = decVal
-			g.VariableMap[val.Value.(string)] = temp
-		case g.VariableMap[val.Value.(string)].Type == Float:
-			decVal := g.VariableMap[val.Value.(string)].Value.(float64) - 1
-			temp := g.VariableMap[val.Value.(string)]
+			g.setVariable(val.Value.(string), temp)
+		case g.variable(val.Value.(string)).Type == Float:
+			decVal := g.variable(val.Value.(string)).Value.(float64) - 1
+			temp := g.variable(val.Value.(string))
 			temp.Value = decVal
-			g.VariableMap[val.Value.(string)] = temp
+			g.setVariable(val.Value.(string), temp)
 		default:
-			return errors.New("ERROR: cannot perform DEC_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform DEC_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform DEC_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform DEC_OP on different types")
 	}
 	return nil
 }
@@ -1291,37 +2301,80 @@ func (g *Gorth) Neg() error {
 		g.Push(StackElement{Type: Bool, Value: !val.Value.(bool)})
 	// variable negation
 	case val.Type == Identifier:
-		_, exists := g.VariableMap[val.Value.(string)]
+		_, exists := g.lookupVariable(val.Value.(string))
 
 		if !exists {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val.Value.(string))
 		}
 
-		switch g.VariableMap[val.Value.(string)].Type {
+		switch g.variable(val.Value.(string)).Type {
 		case Int:
-			negVal := -g.VariableMap[val.Value.(string)].Value.(int)
-			temp := g.VariableMap[val.Value.(string)]
+			negVal := -g.variable(val.Value.(string)).Value.(int)
+			temp := g.variable(val.Value.(string))
 			temp.Value = negVal
-			g.VariableMap[val.Value.(string)] = temp
+			g.setVariable(val.Value.(string), temp)
 		case Float:
-			negVal := -g.VariableMap[val.Value.(string)].Value.(float64)
-			temp := g.VariableMap[val.Value.(string)]
+			negVal := -g.variable(val.Value.(string)).Value.(float64)
+			temp := g.variable(val.Value.(string))
 			temp.Value = negVal
-			g.VariableMap[val.Value.(string)] = temp
+			g.setVariable(val.Value.(string), temp)
 		case Bool:
-			negVal := !g.VariableMap[val.Value.(string)].Value.(bool)
-			temp := g.VariableMap[val.Value.(string)]
+			negVal := !g.variable(val.Value.(string)).Value.(bool)
+			temp := g.variable(val.Value.(string))
 			temp.Value = negVal
-			g.VariableMap[val.Value.(string)] = temp
+			g.setVariable(val.Value.(string), temp)
 		default:
-			return errors.New("ERROR: cannot perform NEG_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform NEG_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform NEG_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform NEG_OP on different types")
 	}
 	return nil
 }
 
+func (g *Gorth) Abs() error {
+	// pushes the absolute value of the top number, looking up variables
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	switch val.Type {
+	case Int:
+		n := val.Value.(int)
+		if n == math.MinInt {
+			return errors.New("ERROR: integer overflow in ABS_OP")
+		}
+		if n < 0 {
+			n = -n
+		}
+		return g.Push(StackElement{Type: Int, Value: n})
+	case Float:
+		return g.Push(StackElement{Type: Float, Value: math.Abs(val.Value.(float64))})
+	default:
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform ABS_OP on non numeric types")
+	}
+}
+
+func (g *Gorth) Sqrt() error {
+	// pushes the square root of the top number, always as a float
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	x, ok := toFloat(val)
+	if !ok {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform SQRT_OP on non numeric types")
+	}
+
+	if x < 0 {
+		return errors.New("ERROR: cannot perform SQRT_OP on a negative number")
+	}
+
+	return g.pushFloat("SQRT_OP", math.Sqrt(x))
+}
+
 func (g *Gorth) Swap() error {
 	val1, err := g.Pop()
 	if err != nil {
@@ -1360,64 +2413,30 @@ func (g *Gorth) And() error {
 		return err
 	}
 
-	switch {
-	case val1.Type == Bool && val2.Type == Bool:
-		g.Push(StackElement{Type: Bool, Value: val1.Value.(bool) && val2.Value.(bool)})
-	// using variables
-	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
-
-		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
-		}
-
-		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
-		}
-
-		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Bool && g.VariableMap[val2.Value.(string)].Type == Bool:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(bool) && g.VariableMap[val2.Value.(string)].Value.(bool)})
-		default:
-			return errors.New("ERROR: cannot perform AND_OP on non boolean types")
-		}
-	// val1 is a variable and val2 is not
-	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-
-		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
-		}
-
-		switch g.VariableMap[val1.Value.(string)].Type {
-		case Bool:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(bool) && val2.Value.(bool)})
-		default:
-			return errors.New("ERROR: cannot perform AND_OP on non boolean types")
-		}
-	// val2 is a variable and val1 is not
-	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+	// resolve variables before asserting on the values,
+	// so a non boolean operand is a type error instead of a panic
+	val1, err = g.resolve(val1)
+	if err != nil {
+		return err
+	}
 
-		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
-		}
+	val2, err = g.resolve(val2)
+	if err != nil {
+		return err
+	}
 
-		switch g.VariableMap[val2.Value.(string)].Type {
-		case Bool:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(bool) && val1.Value.(bool)})
-		default:
-			return errors.New("ERROR: cannot perform AND_OP on non boolean types")
-		}
-	default:
-		return errors.New("ERROR: cannot perform AND_OP on non boolean types")
+	if val1.Type != Bool || val2.Type != Bool {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform AND_OP on non boolean types")
 	}
 
+	g.Push(StackElement{Type: Bool, Value: val1.Value.(bool) && val2.Value.(bool)})
+
 	return nil
 }
 
 func (g *Gorth) Or() error {
+	// checks if either of the top two elements is true
+	// only works if both elements are boolean
 	val1, err := g.Pop()
 	if err != nil {
 		return err
@@ -1428,73 +2447,24 @@ func (g *Gorth) Or() error {
 		return err
 	}
 
-	// check types
-	if val1.Type != Bool && val1.Type != Identifier || val2.Type != Bool && val2.Type != Identifier {
-		return errors.New("ERROR: cannot perform OR_OP on non boolean types")
+	// resolve variables before asserting on the values,
+	// so a non boolean operand is a type error instead of a panic
+	val1, err = g.resolve(val1)
+	if err != nil {
+		return err
 	}
 
-	switch {
-	case val1.Type == Bool && val2.Type == Bool:
-		g.Push(StackElement{Type: Bool, Value: val1.Value.(bool) || val2.Value.(bool)})
-	// using variables
-	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
-
-		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
-		}
-
-		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
-		}
-
-		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Bool && g.VariableMap[val2.Value.(string)].Type == Bool:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(bool) || g.VariableMap[val2.Value.(string)].Value.(bool)})
-		default:
-			return errors.New("ERROR: cannot perform OR_OP on non boolean types")
-		}
-	// val1 is a variable and val2 is not
-	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-
-		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
-		}
-
-		if val2.Type != Bool {
-			return errors.New("ERROR: cannot perform OR_OP on non boolean types")
-		}
-
-		switch g.VariableMap[val1.Value.(string)].Type {
-		case Bool:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(bool) || val2.Value.(bool)})
-		default:
-			return errors.New("ERROR: cannot perform OR_OP on non boolean types")
-		}
-	// val2 is a variable and val1 is not
-	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
-
-		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
-		}
-
-		if val1.Type != Bool {
-			return errors.New("ERROR: cannot perform OR_OP on non boolean types")
-		}
+	val2, err = g.resolve(val2)
+	if err != nil {
+		return err
+	}
 
-		switch g.VariableMap[val2.Value.(string)].Type {
-		case Bool:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(bool) || val1.Value.(bool)})
-		default:
-			return errors.New("ERROR: cannot perform OR_OP on non boolean types")
-		}
-	default:
-		return errors.New("ERROR: cannot perform OR_OP on non boolean types")
+	if val1.Type != Bool || val2.Type != Bool {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform OR_OP on non boolean types")
 	}
 
+	g.Push(StackElement{Type: Bool, Value: val1.Value.(bool) || val2.Value.(bool)})
+
 	return nil
 }
 
@@ -1520,37 +2490,37 @@ func (g *Gorth) Not() error {
 		g.Push(StackElement{Type: Float, Value: 0.0})
 	// variable negation
 	case val1.Type == Identifier:
-		_, exists := g.VariableMap[val1.Value.(string)]
+		_, exists := g.lookupVariable(val1.Value.(string))
 
 		if !exists {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
-		switch g.VariableMap[val1.Value.(string)].Type {
+		switch g.variable(val1.Value.(string)).Type {
 		case Bool:
-			negVal := !g.VariableMap[val1.Value.(string)].Value.(bool)
-			temp := g.VariableMap[val1.Value.(string)]
+			negVal := !g.variable(val1.Value.(string)).Value.(bool)
+			temp := g.variable(val1.Value.(string))
 			temp.Value = negVal
-			g.VariableMap[val1.Value.(string)] = temp
+			g.setVariable(val1.Value.(string), temp)
 		case Int:
-			negVal := g.VariableMap[val1.Value.(string)].Value.(int) * -1
-			temp := g.VariableMap[val1.Value.(string)]
+			negVal := g.variable(val1.Value.(string)).Value.(int) * -1
+			temp := g.variable(val1.Value.(string))
 			temp.Value = negVal
-			g.VariableMap[val1.Value.(string)] = temp
+			g.setVariable(val1.Value.(string), temp)
 		case Float:
-			negVal := g.VariableMap[val1.Value.(string)].Value.(float64) * -1
-			temp := g.VariableMap[val1.Value.(string)]
+			negVal := g.variable(val1.Value.(string)).Value.(float64) * -1
+			temp := g.variable(val1.Value.(string))
 			temp.Value = negVal
-			g.VariableMap[val1.Value.(string)] = temp
+			g.setVariable(val1.Value.(string), temp)
 		default:
-			return errors.New("ERROR: cannot perform NOT_OP on non boolean types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform NOT_OP on non boolean types")
 		}
 
 		// push the variable back onto the stack
 		// we only do this if the value on the stack is a variable
 		g.Push(val1)
 	default:
-		return errors.New("ERROR: cannot perform NOT_OP on non boolean types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform NOT_OP on non boolean types")
 	}
 
 	return nil
@@ -1570,107 +2540,102 @@ func (g *Gorth) Equal() error {
 		return err
 	}
 
-	switch {
-	case val1.Type == Int && val2.Type == Int && val1.Type != Identifier && val2.Type != Identifier:
-		g.Push(StackElement{Type: Bool, Value: val1.Value == val2.Value})
-	case val1.Type == Float && val2.Type == Float && val1.Type != Identifier && val2.Type != Identifier:
-		g.Push(StackElement{Type: Bool, Value: val1.Value == val2.Value})
-	case val1.Type == Int && val2.Type == Float && val1.Type != Identifier && val2.Type != Identifier:
-		g.Push(StackElement{Type: Bool, Value: float64(val1.Value.(int)) == val2.Value.(float64)})
-	case val1.Type == Float && val2.Type == Int && val1.Type != Identifier && val2.Type != Identifier:
-		g.Push(StackElement{Type: Bool, Value: val1.Value.(float64) == float64(val2.Value.(int))})
-	case val1.Type == String && val2.Type == String && val1.Type != Identifier && val2.Type != Identifier:
-		g.Push(StackElement{Type: Bool, Value: val1.Value == val2.Value})
-	case val1.Type == Bool && val2.Type == Bool && val1.Type != Identifier && val2.Type != Identifier:
-		g.Push(StackElement{Type: Bool, Value: val1.Value == val2.Value})
-	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+	equal, err := g.equals(val1, val2)
+	if err != nil {
+		return err
+	}
 
-		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
-		}
+	g.Push(StackElement{Type: Bool, Value: equal})
+	return nil
+}
 
-		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
-		}
+// floatsEqual compares floats within Epsilon, so rounding like 0.1 0.2 + 0.3 == is still true
+func (g *Gorth) floatsEqual(a, b float64) bool {
+	// exact matches first, infinities are equal to themselves but their difference is NaN
+	if a == b {
+		return true
+	}
 
-		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(int) == g.VariableMap[val2.Value.(string)].Value.(int)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(float64) == g.VariableMap[val2.Value.(string)].Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val1.Value.(string)].Value.(int)) == g.VariableMap[val2.Value.(string)].Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(float64) == float64(g.VariableMap[val2.Value.(string)].Value.(int))})
-		case g.VariableMap[val1.Value.(string)].Type == String && g.VariableMap[val2.Value.(string)].Type == String:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(string) == g.VariableMap[val2.Value.(string)].Value.(string)})
-		case g.VariableMap[val1.Value.(string)].Type == Bool && g.VariableMap[val2.Value.(string)].Type == Bool:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(bool) == g.VariableMap[val2.Value.(string)].Value.(bool)})
-		default:
-			g.Push(StackElement{Type: Bool, Value: false})
-		}
-	// val1 is a variable and val2 is not
-	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
+	return math.Abs(a-b) <= g.Epsilon
+}
 
-		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+// equals compares two elements the way EQUAL_OP does, resolving identifiers first
+// ints and floats are compared by value, lists element by element and
+// values of any other combination of types are never equal
+func (g *Gorth) equals(val1, val2 StackElement) (bool, error) {
+	val1, err := g.resolve(val1)
+	if err != nil {
+		return false, err
+	}
+
+	val2, err = g.resolve(val2)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case val1.Type == Int && val2.Type == Int:
+		return val1.Value.(int) == val2.Value.(int), nil
+	case val1.Type == Float && val2.Type == Float:
+		return g.floatsEqual(val1.Value.(float64), val2.Value.(float64)), nil
+	case val1.Type == Int && val2.Type == Float:
+		return g.floatsEqual(float64(val1.Value.(int)), val2.Value.(float64)), nil
+	case val1.Type == Float && val2.Type == Int:
+		return g.floatsEqual(val1.Value.(float64), float64(val2.Value.(int))), nil
+	case val1.Type == String && val2.Type == String:
+		return val1.Value.(string) == val2.Value.(string), nil
+	case val1.Type == Bool && val2.Type == Bool:
+		return val1.Value.(bool) == val2.Value.(bool), nil
+	case val1.Type == List && val2.Type == List:
+		items1 := val1.Value.([]StackElement)
+		items2 := val2.Value.([]StackElement)
+		if len(items1) != len(items2) {
+			return false, nil
 		}
 
-		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(int) == val2.Value.(int)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(float64) == val2.Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val1.Value.(string)].Value.(int)) == val2.Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(float64) == float64(val2.Value.(int))})
-		case g.VariableMap[val1.Value.(string)].Type == String && val2.Type == String:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(string) == val2.Value.(string)})
-		case g.VariableMap[val1.Value.(string)].Type == Bool && val2.Type == Bool:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(bool) == val2.Value.(bool)})
-		default:
-			g.Push(StackElement{Type: Bool, Value: false})
+		for i := range items1 {
+			equal, err := g.equals(items1[i], items2[i])
+			if err != nil || !equal {
+				return false, err
+			}
 		}
-	// val2 is a variable and val1 is not
-	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
 
-		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+		return true, nil
+	case val1.Type == Map && val2.Type == Map:
+		entries1 := val1.Value.(map[string]StackElement)
+		entries2 := val2.Value.(map[string]StackElement)
+		if len(entries1) != len(entries2) {
+			return false, nil
 		}
 
-		switch {
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(int) == val1.Value.(int)})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) == val1.Value.(float64)})
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val2.Value.(string)].Value.(int)) == val1.Value.(float64)})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) == float64(val1.Value.(int))})
-		case g.VariableMap[val2.Value.(string)].Type == String && val1.Type == String:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(string) == val1.Value.(string)})
-		case g.VariableMap[val2.Value.(string)].Type == Bool && val1.Type == Bool:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(bool) == val1.Value.(bool)})
-		default:
-			g.Push(StackElement{Type: Bool, Value: false})
+		for key, entry1 := range entries1 {
+			entry2, exists := entries2[key]
+			if !exists {
+				return false, nil
+			}
+
+			equal, err := g.equals(entry1, entry2)
+			if err != nil || !equal {
+				return false, err
+			}
 		}
+
+		return true, nil
 	default:
-		g.Push(StackElement{Type: Bool, Value: false})
+		return false, nil
 	}
-	return nil
 }
 
-func (g *Gorth) NotEqual() {
+func (g *Gorth) NotEqual() error {
 	// checks if the top elements are not equal
 	// equality checking is independent of type
 	// maybe bad language design lol
-	g.Equal()
-	g.Not()
+	err := g.Equal()
+	if err != nil {
+		return err
+	}
+
+	return g.Not()
 }
 
 func (g *Gorth) EqualType() error {
@@ -1691,36 +2656,36 @@ func (g *Gorth) EqualType() error {
 	case val1.Type == val2.Type && val1.Type != Identifier && val2.Type != Identifier:
 		g.Push(StackElement{Type: Bool, Value: true})
 	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
-		g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Type == g.VariableMap[val2.Value.(string)].Type})
+		g.Push(StackElement{Type: Bool, Value: g.variable(val1.Value.(string)).Type == g.variable(val2.Value.(string)).Type})
 	// val1 is a variable and val2 is not
 	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
-		g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Type == val2.Type})
+		g.Push(StackElement{Type: Bool, Value: g.variable(val1.Value.(string)).Type == val2.Type})
 	// val2 is a variable and val1 is not
 	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
-		g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Type == val1.Type})
+		g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Type == val1.Type})
 	default:
 		g.Push(StackElement{Type: Bool, Value: false})
 	}
@@ -1750,71 +2715,71 @@ func (g *Gorth) GreaterThan() error {
 		g.Push(StackElement{Type: Bool, Value: float64(val2.Value.(int)) > val1.Value.(float64)}) // Comparing val2 to val1
 	// using variables
 	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(int) > g.VariableMap[val1.Value.(string)].Value.(int)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) > g.VariableMap[val1.Value.(string)].Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) > float64(g.VariableMap[val1.Value.(string)].Value.(int))})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Int:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val2.Value.(string)].Value.(int)) > g.VariableMap[val1.Value.(string)].Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(int) > g.variable(val1.Value.(string)).Value.(int)})
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) > g.variable(val1.Value.(string)).Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) > float64(g.variable(val1.Value.(string)).Value.(int))})
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Int:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val2.Value.(string)).Value.(int)) > g.variable(val1.Value.(string)).Value.(float64)})
 		default:
-			return errors.New("ERROR: cannot perform GT_THAN_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform GT_THAN_OP on different types")
 		}
 	// val1 is a variable and val2 is not
 	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(int) > val2.Value.(int)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(float64) > val2.Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(float64) > float64(val2.Value.(int))})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val1.Value.(string)].Value.(int)) > val2.Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val1.Value.(string)).Value.(int) > val2.Value.(int)})
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val1.Value.(string)).Value.(float64) > val2.Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val1.Value.(string)).Value.(float64) > float64(val2.Value.(int))})
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val1.Value.(string)).Value.(int)) > val2.Value.(float64)})
 		default:
-			return errors.New("ERROR: cannot perform GT_THAN_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform GT_THAN_OP on different types")
 		}
 	// val2 is a variable and val1 is not
 	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(int) > val1.Value.(int)})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) > val1.Value.(float64)})
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val2.Value.(string)].Value.(int)) > val1.Value.(float64)})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) > float64(val1.Value.(int))})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(int) > val1.Value.(int)})
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) > val1.Value.(float64)})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val2.Value.(string)).Value.(int)) > val1.Value.(float64)})
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) > float64(val1.Value.(int))})
 		default:
-			return errors.New("ERROR: cannot perform GT_THAN_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform GT_THAN_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform GT_THAN_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform GT_THAN_OP on different types")
 	}
 	return nil
 }
@@ -1841,71 +2806,71 @@ func (g *Gorth) LessThan() error {
 		g.Push(StackElement{Type: Bool, Value: float64(val2.Value.(int)) < val1.Value.(float64)})
 	// using variables
 	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(int) < g.VariableMap[val1.Value.(string)].Value.(int)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) < g.VariableMap[val1.Value.(string)].Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val2.Value.(string)].Value.(int)) < g.VariableMap[val1.Value.(string)].Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) < float64(g.VariableMap[val1.Value.(string)].Value.(int))})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(int) < g.variable(val1.Value.(string)).Value.(int)})
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) < g.variable(val1.Value.(string)).Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Float:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val2.Value.(string)).Value.(int)) < g.variable(val1.Value.(string)).Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) < float64(g.variable(val1.Value.(string)).Value.(int))})
 		default:
-			return errors.New("ERROR: cannot perform LS_THAN_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform LS_THAN_OP on different types")
 		}
 	// val1 is a variable and val2 is not
 	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(int) < val2.Value.(int)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(float64) < val2.Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val1.Value.(string)].Value.(int)) < val2.Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val1.Value.(string)].Value.(int)) < val2.Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val1.Value.(string)).Value.(int) < val2.Value.(int)})
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val1.Value.(string)).Value.(float64) < val2.Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val1.Value.(string)).Value.(int)) < val2.Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val1.Value.(string)).Value.(int)) < val2.Value.(float64)})
 		default:
-			return errors.New("ERROR: cannot perform LS_THAN_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform LS_THAN_OP on different types")
 		}
 	// val2 is a variable and val1 is not
 	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(int) < val1.Value.(int)})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) < val1.Value.(float64)})
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val2.Value.(string)].Value.(int)) < val1.Value.(float64)})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) < val1.Value.(float64)})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(int) < val1.Value.(int)})
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) < val1.Value.(float64)})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val2.Value.(string)).Value.(int)) < val1.Value.(float64)})
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) < val1.Value.(float64)})
 		default:
-			return errors.New("ERROR: cannot perform LS_THAN_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform LS_THAN_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform LS_THAN_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform LS_THAN_OP on different types")
 	}
 	return nil
 }
@@ -1932,71 +2897,71 @@ func (g *Gorth) GreaterThanEqual() error {
 		g.Push(StackElement{Type: Bool, Value: float64(val2.Value.(int)) >= val1.Value.(float64)})
 	// using variables
 	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(int) >= g.VariableMap[val1.Value.(string)].Value.(int)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) >= g.VariableMap[val1.Value.(string)].Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) >= float64(g.VariableMap[val1.Value.(string)].Value.(int))})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Int:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val2.Value.(string)].Value.(int)) >= g.VariableMap[val1.Value.(string)].Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(int) >= g.variable(val1.Value.(string)).Value.(int)})
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) >= g.variable(val1.Value.(string)).Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) >= float64(g.variable(val1.Value.(string)).Value.(int))})
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Int:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val2.Value.(string)).Value.(int)) >= g.variable(val1.Value.(string)).Value.(float64)})
 		default:
-			return errors.New("ERROR: cannot perform GT_THAN_EQ_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform GT_THAN_EQ_OP on different types")
 		}
 	// val1 is a variable and val2 is not
 	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(int) >= val2.Value.(int)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(float64) >= val2.Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val1.Value.(string)].Value.(int)) >= val2.Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val1.Value.(string)].Value.(int)) >= val2.Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val1.Value.(string)).Value.(int) >= val2.Value.(int)})
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val1.Value.(string)).Value.(float64) >= val2.Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val1.Value.(string)).Value.(int)) >= val2.Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val1.Value.(string)).Value.(int)) >= val2.Value.(float64)})
 		default:
-			return errors.New("ERROR: cannot perform GT_THAN_EQ_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform GT_THAN_EQ_OP on different types")
 		}
 	// val2 is a variable and val1 is not
 	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(int) >= val1.Value.(int)})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) >= val1.Value.(float64)})
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val2.Value.(string)].Value.(int)) >= val1.Value.(float64)})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val2.Value.(string)].Value.(int)) >= val1.Value.(float64)})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(int) >= val1.Value.(int)})
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) >= val1.Value.(float64)})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val2.Value.(string)).Value.(int)) >= val1.Value.(float64)})
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val2.Value.(string)).Value.(int)) >= val1.Value.(float64)})
 		default:
-			return errors.New("ERROR: cannot perform GT_THAN_EQ_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform GT_THAN_EQ_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform GT_THAN_EQ_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform GT_THAN_EQ_OP on different types")
 	}
 	return nil
 }
@@ -2023,71 +2988,71 @@ func (g *Gorth) LessThanEqual() error {
 		g.Push(StackElement{Type: Bool, Value: float64(val2.Value.(int)) <= val1.Value.(float64)})
 	// using variables
 	case val1.Type == Identifier && val2.Type == Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(int) <= g.VariableMap[val1.Value.(string)].Value.(int)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) <= g.VariableMap[val1.Value.(string)].Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Int && g.VariableMap[val2.Value.(string)].Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val2.Value.(string)].Value.(int)) <= g.VariableMap[val1.Value.(string)].Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && g.VariableMap[val2.Value.(string)].Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) <= float64(g.VariableMap[val1.Value.(string)].Value.(int))})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(int) <= g.variable(val1.Value.(string)).Value.(int)})
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) <= g.variable(val1.Value.(string)).Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && g.variable(val2.Value.(string)).Type == Float:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val2.Value.(string)).Value.(int)) <= g.variable(val1.Value.(string)).Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Float && g.variable(val2.Value.(string)).Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) <= float64(g.variable(val1.Value.(string)).Value.(int))})
 		default:
-			return errors.New("ERROR: cannot perform LS_THAN_EQ_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform LS_THAN_EQ_OP on different types")
 		}
 	// val1 is a variable and val2 is not
 	case val1.Type == Identifier && val2.Type != Identifier:
-		_, exists1 := g.VariableMap[val1.Value.(string)]
+		_, exists1 := g.lookupVariable(val1.Value.(string))
 
 		if !exists1 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val1.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val1.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(int) <= val2.Value.(int)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val1.Value.(string)].Value.(float64) <= val2.Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Int && val2.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val1.Value.(string)].Value.(int)) <= val2.Value.(float64)})
-		case g.VariableMap[val1.Value.(string)].Type == Float && val2.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val1.Value.(string)].Value.(int)) <= val2.Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val1.Value.(string)).Value.(int) <= val2.Value.(int)})
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val1.Value.(string)).Value.(float64) <= val2.Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Int && val2.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val1.Value.(string)).Value.(int)) <= val2.Value.(float64)})
+		case g.variable(val1.Value.(string)).Type == Float && val2.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val1.Value.(string)).Value.(int)) <= val2.Value.(float64)})
 		default:
-			return errors.New("ERROR: cannot perform LS_THAN_EQ_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform LS_THAN_EQ_OP on different types")
 		}
 	// val2 is a variable and val1 is not
 	case val1.Type != Identifier && val2.Type == Identifier:
-		_, exists2 := g.VariableMap[val2.Value.(string)]
+		_, exists2 := g.lookupVariable(val2.Value.(string))
 
 		if !exists2 {
-			return fmt.Errorf("ERROR: variable %v has not been declared", val2.Value.(string))
+			return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val2.Value.(string))
 		}
 
 		switch {
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(int) <= val1.Value.(int)})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) <= val1.Value.(float64)})
-		case g.VariableMap[val2.Value.(string)].Type == Int && val1.Type == Float:
-			g.Push(StackElement{Type: Bool, Value: float64(g.VariableMap[val2.Value.(string)].Value.(int)) <= val1.Value.(float64)})
-		case g.VariableMap[val2.Value.(string)].Type == Float && val1.Type == Int:
-			g.Push(StackElement{Type: Bool, Value: g.VariableMap[val2.Value.(string)].Value.(float64) <= val1.Value.(float64)})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(int) <= val1.Value.(int)})
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) <= val1.Value.(float64)})
+		case g.variable(val2.Value.(string)).Type == Int && val1.Type == Float:
+			g.Push(StackElement{Type: Bool, Value: float64(g.variable(val2.Value.(string)).Value.(int)) <= val1.Value.(float64)})
+		case g.variable(val2.Value.(string)).Type == Float && val1.Type == Int:
+			g.Push(StackElement{Type: Bool, Value: g.variable(val2.Value.(string)).Value.(float64) <= val1.Value.(float64)})
 		default:
-			return errors.New("ERROR: cannot perform LS_THAN_EQ_OP on different types")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform LS_THAN_EQ_OP on different types")
 		}
 	default:
-		return errors.New("ERROR: cannot perform LS_THAN_EQ_OP on different types")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform LS_THAN_EQ_OP on different types")
 	}
 	return nil
 }
@@ -2096,230 +3061,3401 @@ func (g *Gorth) VarAssign() error {
 	val1, err := g.Pop()
 
 	if err != nil {
-		return errors.New("ERROR: stack is empty, cannot assign from an empty stack")
+		return wrapError(ErrStackEmpty, "ERROR: stack is empty, cannot assign from an empty stack")
 	}
 
 	// this should be the variable on the stack
 	val2, err := g.Pop()
 
 	if err != nil {
-		return errors.New("ERROR: stack is empty, cannot assign from an empty stack")
+		return wrapError(ErrStackEmpty, "ERROR: stack is empty, cannot assign from an empty stack")
 	}
 
 	// if this value is not an identifier
 	if val2.Type != Identifier {
-		return errors.New("ERROR: cannot assign a value to a non-variable")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot assign a value to a non-variable")
 	}
 
 	// if the value is an identifier
 	// check if the variable has been declared
 	// if it has, update the value
-	variable, exists := g.VariableMap[val2.Value.(string)]
+	variable, exists := g.lookupVariable(val2.Value.(string))
 
 	if !exists {
-		return fmt.Errorf("ERROR: variable %v has not been declared on the stack", val2.Value.(string))
+		return wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared on the stack", val2.Value.(string))
 	}
 
-	if g.VariableMap[val2.Value.(string)].Const {
+	if g.variable(val2.Value.(string)).Const {
 		return fmt.Errorf("ERROR: variable %v is a constant and cannot be reassigned", val2.Value.(string))
 	}
 
+	// with dynamic typing any variable type can replace the old one
+	if g.DynamicTyping {
+		switch val1.Type {
+		case Int, Float, Bool, String:
+			g.setVariable(val2.Value.(string), Variable{Type: val1.Type, Value: val1.Value, Name: val2.Value.(string), Const: false})
+			return nil
+		}
+	}
+
 	// change the value of the variable in the variable map
 	switch variable.Type {
 	case Int:
 		if val1.Type == Int {
-			g.VariableMap[val2.Value.(string)] = Variable{Type: Int, Value: val1.Value.(int), Name: val2.Value.(string), Const: false}
+			g.setVariable(val2.Value.(string), Variable{Type: Int, Value: val1.Value.(int), Name: val2.Value.(string), Const: false})
 		} else {
-			return errors.New("ERROR: cannot assign a non-integer value to an integer variable")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot assign a non-integer value to an integer variable")
 		}
 	case Float:
 		if val1.Type == Float {
-			g.VariableMap[val2.Value.(string)] = Variable{Type: Float, Value: val1.Value.(float64), Name: val2.Value.(string), Const: false}
+			g.setVariable(val2.Value.(string), Variable{Type: Float, Value: val1.Value.(float64), Name: val2.Value.(string), Const: false})
 		} else {
-			return errors.New("ERROR: cannot assign a non-float value to a float variable")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot assign a non-float value to a float variable")
 		}
 	case Bool:
 		if val1.Type == Bool {
-			g.VariableMap[val2.Value.(string)] = Variable{Type: Bool, Value: val1.Value.(bool), Name: val2.Value.(string), Const: false}
+			g.setVariable(val2.Value.(string), Variable{Type: Bool, Value: val1.Value.(bool), Name: val2.Value.(string), Const: false})
 		} else {
-			return errors.New("ERROR: cannot assign a non-boolean value to a boolean variable")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot assign a non-boolean value to a boolean variable")
 		}
 	case String:
 		if val1.Type == String {
-			g.VariableMap[val2.Value.(string)] = Variable{Type: String, Value: val1.Value.(string), Name: val2.Value.(string), Const: false}
+			g.setVariable(val2.Value.(string), Variable{Type: String, Value: val1.Value.(string), Name: val2.Value.(string), Const: false})
 		} else {
-			return errors.New("ERROR: cannot assign a non-string value to a string variable")
+			return wrapError(ErrTypeMismatch, "ERROR: cannot assign a non-string value to a string variable")
 		}
 	default:
-		return errors.New("ERROR: cannot assign a value to a non-variable")
+		return wrapError(ErrTypeMismatch, "ERROR: cannot assign a value to a non-variable")
 	}
 
 	return nil
 }
 
-func (g *Gorth) PrintStack() {
-	fmt.Printf("Program stack: %v\n", g.ExecStack)
+// scope returns the i-th scope of the scope stack, scope 0 is VariableMap and the scopes opened by
+// scope ... end blocks follow it, innermost last
+func (g *Gorth) scope(i int) map[string]Variable {
+	if i == 0 {
+		return g.VariableMap
+	}
+
+	return g.scopes[i-1]
 }
 
-func (g *Gorth) ExecuteProgram(program []StackElement) error {
-	for _, op := range program {
-		if g.DebugMode {
-			fmt.Println("Current operation: " + fmt.Sprintf("%v", op.Type == Operator))
-			fmt.Println("Current Stack: ", g.ExecStack)
+// findScope searches the scope stack outward from the innermost scope and returns the first one
+// that declares name, so a variable in a scope shadows one with the same name outside it
+func (g *Gorth) findScope(name string) (map[string]Variable, bool) {
+	for i := len(g.scopes); i >= 0; i-- {
+		if _, exists := g.scope(i)[name]; exists {
+			return g.scope(i), true
 		}
+	}
 
-		if op.Type == Operator {
-			switch op.Value {
-			case ADD_OP:
-				err := g.Add()
-				if err != nil {
-					return err
-				}
-			case SUB_OP:
-				err := g.Sub()
-				if err != nil {
-					return err
-				}
-			case MUL_OP:
-				err := g.Mul()
-				if err != nil {
-					return err
-				}
-			case DIV_OP:
-				err := g.Div()
-				if err != nil {
-					return err
-				}
-			case MOD_OP:
-				err := g.Mod()
-				if err != nil {
-					return err
-				}
-			case EXP_OP:
-				err := g.Exp()
-				if err != nil {
-					return err
-				}
-			case INC_OP:
-				err := g.Inc()
-				if err != nil {
-					return err
-				}
-			case DEC_OP:
-				err := g.Dec()
-				if err != nil {
-					return err
-				}
-			case SWAP_OP:
-				err := g.Swap()
-				if err != nil {
-					return err
-				}
-			case DUP_OP:
-				err := g.Dup()
-				if err != nil {
-					return err
-				}
-			case DROP_OP:
-				err := g.Drop()
-				if err != nil {
-					return err
-				}
-			case DUMP_OP:
-				err := g.Dump()
-				if err != nil {
-					return err
-				}
+	return nil, false
+}
+
+// lookupVariable returns the variable findScope finds for name
+func (g *Gorth) lookupVariable(name string) (Variable, bool) {
+	scope, exists := g.findScope(name)
+	if !exists {
+		return Variable{}, false
+	}
+
+	return scope[name], true
+}
+
+// variable returns the variable lookupVariable finds, or the zero Variable if name is not declared
+func (g *Gorth) variable(name string) Variable {
+	variable, _ := g.lookupVariable(name)
+	return variable
+}
+
+// setVariable replaces name in the scope that declares it, a new variable goes in VariableMap
+func (g *Gorth) setVariable(name string, variable Variable) {
+	scope, exists := g.findScope(name)
+	if !exists {
+		scope = g.VariableMap
+	}
+
+	scope[name] = variable
+}
+
+// deleteVariable removes name from the scope that declares it
+func (g *Gorth) deleteVariable(name string) {
+	if scope, exists := g.findScope(name); exists {
+		delete(scope, name)
+	}
+}
+
+// declare adds a variable to the innermost open scope, it can shadow a variable from an outer
+// scope but not one declared in the same scope
+func (g *Gorth) declare(variable Variable) error {
+	scope := g.scope(len(g.scopes))
+	if _, exists := scope[variable.Name]; exists {
+		return fmt.Errorf("variable %s has already been declared", variable.Name)
+	}
+
+	scope[variable.Name] = variable
+	return nil
+}
+
+// resolve returns the value an identifier refers to, or the element itself
+// if it is not an identifier
+func (g *Gorth) resolve(val StackElement) (StackElement, error) {
+	if val.Type != Identifier {
+		return val, nil
+	}
+
+	variable, exists := g.lookupVariable(val.Value.(string))
+
+	if !exists {
+		return StackElement{}, wrapError(ErrUndeclaredVariable, "ERROR: variable %v has not been declared", val.Value.(string))
+	}
+
+	return StackElement{Type: variable.Type, Value: variable.Value}, nil
+}
+
+// popValue pops the top element and resolves it if it is an identifier
+func (g *Gorth) popValue() (StackElement, error) {
+	val, err := g.Pop()
+	if err != nil {
+		return StackElement{}, err
+	}
+
+	return g.resolve(val)
+}
+
+func (g *Gorth) Fib() error {
+	// pushes the nth fibonacci number, computed iteratively
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform FIB_OP on non integer types")
+	}
+
+	n := val.Value.(int)
+	if n < 0 {
+		return errors.New("ERROR: cannot perform FIB_OP on a negative number")
+	}
+
+	if n == 0 {
+		return g.Push(StackElement{Type: Int, Value: 0})
+	}
+
+	a, b := 0, 1
+	for i := 1; i < n; i++ {
+		if b > math.MaxInt-a {
+			return errors.New("ERROR: integer overflow in FIB_OP")
+		}
+		a, b = b, a+b
+	}
+
+	return g.Push(StackElement{Type: Int, Value: b})
+}
+
+func (g *Gorth) Prime() error {
+	// checks if the top of the stack is prime using trial division
+	// numbers less than 2 are not prime
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform PRIME_OP on non integer types")
+	}
+
+	n := val.Value.(int)
+	isPrime := n >= 2
+	for i := 2; isPrime && i <= n/i; i++ {
+		if n%i == 0 {
+			isPrime = false
+		}
+	}
+
+	return g.Push(StackElement{Type: Bool, Value: isPrime})
+}
+
+func (g *Gorth) RevBits() error {
+	// reverses the low `width` bits of the value beneath the width
+	// bits of the value above the width are masked off before reversing,
+	// so 0b10110 4 revbits only reverses 0b0110
+	width, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if width.Type != Int || val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform REVBITS_OP on non integer types")
+	}
+
+	w := width.Value.(int)
+	if w < 1 || w > 64 {
+		return errors.New("ERROR: REVBITS_OP width must be between 1 and 64")
+	}
+
+	reversed := bits.Reverse64(uint64(val.Value.(int))) >> (64 - w)
+
+	return g.Push(StackElement{Type: Int, Value: int(reversed)})
+}
+
+func (g *Gorth) PopCount() error {
+	// counts the set bits of the top of the stack
+	// negative numbers are counted in their 64 bit two's complement form,
+	// so -1 popcount is 64
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform POPCOUNT_OP on non integer types")
+	}
+
+	return g.Push(StackElement{Type: Int, Value: bits.OnesCount64(uint64(val.Value.(int)))})
+}
+
+func (g *Gorth) ByteSwap() error {
+	// reverses the byte order of the value beneath the width
+	// the value is truncated to the given number of bytes before swapping
+	width, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if width.Type != Int || val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform BSWAP_OP on non integer types")
+	}
+
+	var swapped int
+	switch width.Value.(int) {
+	case 2:
+		swapped = int(bits.ReverseBytes16(uint16(val.Value.(int))))
+	case 4:
+		swapped = int(bits.ReverseBytes32(uint32(val.Value.(int))))
+	case 8:
+		swapped = int(bits.ReverseBytes64(uint64(val.Value.(int))))
+	default:
+		return errors.New("ERROR: BSWAP_OP width must be 2, 4 or 8 bytes")
+	}
+
+	return g.Push(StackElement{Type: Int, Value: swapped})
+}
+
+func (g *Gorth) Pair() error {
+	// pops the top two elements and pushes them as a two element list
+	// the list keeps stack order, so 1 2 pair is [ 1 2 ]
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform PAIR_OP")
+	}
+
+	second, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	first, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	return g.Push(StackElement{Type: List, Value: []StackElement{copyElement(first), copyElement(second)}})
+}
+
+func (g *Gorth) Unpair() error {
+	// pushes the two elements of a two element list back onto the stack in order
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != List {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform UNPAIR_OP on non list types")
+	}
+
+	items := val.Value.([]StackElement)
+	if len(items) != 2 {
+		return fmt.Errorf("ERROR: UNPAIR_OP expects a list of 2 elements, but got %d", len(items))
+	}
+
+	for _, item := range items {
+		err = g.Push(copyElement(item))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *Gorth) Pick() error {
+	// copies the element n positions below the top of the stack to the top
+	// 0 pick behaves like dup
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform PICK_OP with a non integer index")
+	}
+
+	n := val.Value.(int)
+	if n < 0 || n >= len(g.ExecStack) {
+		return fmt.Errorf("ERROR: PICK_OP index %d is out of range for a stack of %d elements", n, len(g.ExecStack))
+	}
+
+	return g.Push(copyElement(g.ExecStack[len(g.ExecStack)-1-n]))
+}
+
+// peekList returns the items of the non empty list on top of the stack without popping it
+func (g *Gorth) peekList(op string) ([]StackElement, error) {
+	top, err := g.Peek()
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := g.resolve(top)
+	if err != nil {
+		return nil, err
+	}
+
+	if val.Type != List {
+		return nil, wrapError(ErrTypeMismatch, "ERROR: cannot perform %v on non list types", op)
+	}
+
+	items := val.Value.([]StackElement)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("ERROR: cannot perform %v on an empty list", op)
+	}
+
+	return items, nil
+}
+
+func (g *Gorth) Head() error {
+	// pushes a copy of the first element of the list on top of the stack, leaving the list in place
+	items, err := g.peekList("HEAD_OP")
+	if err != nil {
+		return err
+	}
+
+	return g.Push(copyElement(items[0]))
+}
+
+func (g *Gorth) Last() error {
+	// pushes a copy of the last element of the list on top of the stack, leaving the list in place
+	items, err := g.peekList("LAST_OP")
+	if err != nil {
+		return err
+	}
+
+	return g.Push(copyElement(items[len(items)-1]))
+}
+
+func (g *Gorth) Tail() error {
+	// pops a list and pushes a new list without its first element
+	// an empty list has no tail, so it is an error like head and last
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != List {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform TAIL_OP on non list types")
+	}
+
+	items := val.Value.([]StackElement)
+	if len(items) == 0 {
+		return errors.New("ERROR: cannot perform TAIL_OP on an empty list")
+	}
+
+	return g.Push(copyElement(StackElement{Type: List, Value: items[1:]}))
+}
+
+func (g *Gorth) Cons() error {
+	// pops a list and a value and pushes a new list with the value at the front
+	// eg. 1 [ 2 3 ] cons is [ 1 2 3 ]
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform CONS_OP")
+	}
+
+	list, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if list.Type != List {
+		return wrapError(ErrTypeMismatch, "ERROR: CONS_OP expects a list on top of the stack")
+	}
+
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	items := append([]StackElement{val}, list.Value.([]StackElement)...)
+	return g.Push(copyElement(StackElement{Type: List, Value: items}))
+}
+
+// popList pops the list on top of the stack and returns its items
+func (g *Gorth) popList(op string) ([]StackElement, error) {
+	val, err := g.popValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if val.Type != List {
+		return nil, wrapError(ErrTypeMismatch, "ERROR: cannot perform %v on non list types", op)
+	}
+
+	return val.Value.([]StackElement), nil
+}
+
+func (g *Gorth) Dot() error {
+	// pops two numeric lists of the same length and pushes the sum of their pairwise products
+	// eg. [ 1 2 3 ] [ 4 5 6 ] dot is 32, the result is a Float if any element is a Float
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform DOT_OP")
+	}
+
+	second, err := g.popList("DOT_OP")
+	if err != nil {
+		return err
+	}
+
+	first, err := g.popList("DOT_OP")
+	if err != nil {
+		return err
+	}
+
+	if len(first) != len(second) {
+		return fmt.Errorf("ERROR: DOT_OP expects lists of the same length, but got %d and %d", len(first), len(second))
+	}
+
+	intSum := 0
+	floatSum := 0.0
+	isFloat := false
+	for i := range first {
+		if first[i].Type == Int && second[i].Type == Int {
+			intSum += first[i].Value.(int) * second[i].Value.(int)
+			continue
+		}
+
+		x, ok1 := toFloat(first[i])
+		y, ok2 := toFloat(second[i])
+		if !ok1 || !ok2 {
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform DOT_OP on non numeric elements")
+		}
+
+		isFloat = true
+		floatSum += x * y
+	}
+
+	if isFloat {
+		return g.pushFloat("DOT_OP", floatSum+float64(intSum))
+	}
+
+	return g.Push(StackElement{Type: Int, Value: intSum})
+}
+
+func (g *Gorth) Argmin() error {
+	// pops a numeric list and pushes the index of its smallest element, the first one on ties
+	return g.argExtreme("ARGMIN_OP", func(x, best float64) bool { return x < best })
+}
+
+func (g *Gorth) Argmax() error {
+	// pops a numeric list and pushes the index of its largest element, the first one on ties
+	// eg. [ 3 1 4 1 5 ] argmax is 4
+	return g.argExtreme("ARGMAX_OP", func(x, best float64) bool { return x > best })
+}
+
+// argExtreme pushes the index of the first element of the popped list that no later element beats
+func (g *Gorth) argExtreme(op string, beats func(x, best float64) bool) error {
+	items, err := g.popList(op)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return fmt.Errorf("ERROR: cannot perform %v on an empty list", op)
+	}
+
+	index := 0
+	best := 0.0
+	for i, item := range items {
+		x, ok := toFloat(item)
+		if !ok {
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform %v on non numeric elements", op)
+		}
+
+		if i == 0 || beats(x, best) {
+			index = i
+			best = x
+		}
+	}
+
+	return g.Push(StackElement{Type: Int, Value: index})
+}
+
+func (g *Gorth) LTake() error {
+	// pops a count n and a list, pushing the first n elements of the list
+	// eg. [ 1 2 3 4 ] 2 ltake is [ 1 2 ], n past the end of the list takes all of it
+	items, n, err := g.popListAndCount("LTAKE_OP")
+	if err != nil {
+		return err
+	}
+
+	return g.Push(copyElement(StackElement{Type: List, Value: items[:n]}))
+}
+
+func (g *Gorth) LDrop() error {
+	// pops a count n and a list, pushing the list without its first n elements
+	// eg. [ 1 2 3 4 ] 2 ldrop is [ 3 4 ], n past the end of the list leaves it empty
+	items, n, err := g.popListAndCount("LDROP_OP")
+	if err != nil {
+		return err
+	}
+
+	return g.Push(copyElement(StackElement{Type: List, Value: items[n:]}))
+}
+
+// popListAndCount pops a non negative count and the list beneath it, clamping the count to the list length
+func (g *Gorth) popListAndCount(op string) ([]StackElement, int, error) {
+	if len(g.ExecStack) < 2 {
+		return nil, 0, wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform %v", op)
+	}
+
+	count, err := g.popValue()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if count.Type != Int {
+		return nil, 0, wrapError(ErrTypeMismatch, "ERROR: %v expects an integer count on top of the stack", op)
+	}
+
+	n := count.Value.(int)
+	if n < 0 {
+		return nil, 0, fmt.Errorf("ERROR: cannot perform %v with a negative count", op)
+	}
+
+	items, err := g.popList(op)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if n > len(items) {
+		n = len(items)
+	}
+
+	return items, n, nil
+}
+
+func (g *Gorth) Apply() error {
+	// pops the name of an operator and runs that operator, eg. 3 4 "+" apply is 7
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: APPLY_OP expects an operator name on top of the stack")
+	}
+
+	op, exists := operatorMap[val.Value.(string)]
+	if !exists {
+		return fmt.Errorf("ERROR: unknown operator %v for APPLY_OP", val.Value.(string))
+	}
+
+	return g.execute([]StackElement{{Type: Operator, Value: op}})
+}
+
+func (g *Gorth) Ifte() error {
+	// pops an else quotation, a then quotation and a bool, running the then quotation if the bool is true
+	// and the else quotation otherwise, eg. true { "yes" } { "no" } ifte is "yes"
+	if len(g.ExecStack) < 3 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 3 elements need to be on stack to perform IFTE_OP")
+	}
+
+	elseQuotation, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	thenQuotation, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if elseQuotation.Type != Quotation || thenQuotation.Type != Quotation {
+		return wrapError(ErrTypeMismatch, "ERROR: IFTE_OP expects two quotations on top of the stack")
+	}
+
+	condition, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if condition.Type != Bool {
+		return wrapError(ErrTypeMismatch, "ERROR: IFTE_OP expects a bool condition beneath its quotations")
+	}
+
+	if condition.Value.(bool) {
+		return g.runQuotation(thenQuotation)
+	}
+
+	return g.runQuotation(elseQuotation)
+}
+
+func (g *Gorth) Tabulate() error {
+	// pops a quotation and a count n, runs the quotation once for every index from 0 to n-1
+	// with the index pushed, and pushes a list of what each run left on the stack
+	// eg. 5 { dup * } tabulate is [ 0 1 4 9 16 ]
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform TABULATE_OP")
+	}
+
+	quotation, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if quotation.Type != Quotation {
+		return wrapError(ErrTypeMismatch, "ERROR: TABULATE_OP expects a quotation on top of the stack")
+	}
+
+	count, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if count.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform TABULATE_OP with a non integer count")
+	}
+
+	n := count.Value.(int)
+	if n < 0 {
+		return errors.New("ERROR: cannot perform TABULATE_OP with a negative count")
+	}
+
+	base := len(g.ExecStack)
+	items := []StackElement{}
+	for i := 0; i < n; i++ {
+		err = g.Push(StackElement{Type: Int, Value: i})
+		if err != nil {
+			return err
+		}
+
+		err = g.runQuotation(quotation)
+		if err != nil {
+			return err
+		}
+
+		if len(g.ExecStack) < base {
+			return errors.New("ERROR: TABULATE_OP quotation consumed elements below its index")
+		}
+
+		for _, el := range g.ExecStack[base:] {
+			val, err := g.resolve(el)
+			if err != nil {
+				return err
+			}
+			items = append(items, copyElement(val))
+		}
+		g.ExecStack = g.ExecStack[:base]
+	}
+
+	return g.Push(StackElement{Type: List, Value: items})
+}
+
+func (g *Gorth) GoValueType() error {
+	// pushes the name of the go type held by the top element's value
+	// the element is left on the stack and identifiers are not resolved,
+	// since this is meant for debugging the interpreter itself
+	val, err := g.Peek()
+	if err != nil {
+		return err
+	}
+
+	name := "nil"
+	if val.Value != nil {
+		name = reflect.TypeOf(val.Value).String()
+	}
+
+	return g.Push(StackElement{Type: String, Value: name})
+}
+
+func (g *Gorth) Roll() error {
+	// moves the element n positions below the top of the stack to the top
+	// 0 roll does nothing, 1 roll behaves like swap and 2 roll behaves like rot
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform ROLL_OP with a non integer index")
+	}
+
+	n := val.Value.(int)
+	if n < 0 || n >= len(g.ExecStack) {
+		return fmt.Errorf("ERROR: ROLL_OP index %d is out of range for a stack of %d elements", n, len(g.ExecStack))
+	}
+
+	idx := len(g.ExecStack) - 1 - n
+	rolled := g.ExecStack[idx]
+	copy(g.ExecStack[idx:], g.ExecStack[idx+1:])
+	g.ExecStack[len(g.ExecStack)-1] = rolled
+
+	return nil
+}
+
+func (g *Gorth) AssertEqual() error {
+	// expected actual asserteq
+	// always compares the top two values, assertmsg fails with a message
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform ASSERT_EQ_OP")
+	}
+
+	return g.assertEqual("")
+}
+
+func (g *Gorth) AssertMessage() error {
+	// expected actual "message" assertmsg
+	// the message is always required, so a string being compared is never read as one
+	if len(g.ExecStack) < 3 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 3 elements need to be on stack to perform ASSERT_MSG_OP")
+	}
+
+	message, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if message.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: ASSERT_MSG_OP expects a string message, but got %v", message)
+	}
+
+	return g.assertEqual(message.Value.(string))
+}
+
+// assertEqual pops two values and fails unless they are equal, with message if it is not empty
+func (g *Gorth) assertEqual(message string) error {
+	actual, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	expected, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	equal, err := g.equals(expected, actual)
+	if err != nil {
+		return err
+	}
+
+	if !equal {
+		if message != "" {
+			return fmt.Errorf("ERROR: assertion failed: %v (expected %v, got %v)", message, expected.Value, actual.Value)
+		}
+		return fmt.Errorf("ERROR: assertion failed (expected %v, got %v)", expected.Value, actual.Value)
+	}
+
+	return nil
+}
+
+func (g *Gorth) Fill() error {
+	// value n fill pushes n copies of value
+	// 0 fill removes the value entirely
+	countElement, err := g.Pop()
+	if err != nil {
+		return err
+	}
+
+	count, err := g.resolve(countElement)
+	if err != nil {
+		return err
+	}
+
+	if count.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform FILL_OP with a non integer count")
+	}
+
+	n := count.Value.(int)
+	if n < 0 {
+		return errors.New("ERROR: cannot perform FILL_OP with a negative count")
+	}
+
+	val, err := g.Pop()
+	if err != nil {
+		return err
+	}
+
+	// the operands go back before any copy is pushed, so a fill that doesn't fit leaves the stack as it was
+	if len(g.ExecStack)+n > g.MaxStackSize {
+		g.ExecStack = append(g.ExecStack, val, countElement)
+		return wrapError(ErrStackOverflow, "ERROR: stack overflow")
+	}
+
+	for i := 0; i < n; i++ {
+		err = g.Push(copyElement(val))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toFloat returns the value of a numeric element as a float64
+func toFloat(val StackElement) (float64, bool) {
+	switch val.Type {
+	case Int:
+		return float64(val.Value.(int)), true
+	case Float:
+		return val.Value.(float64), true
+	default:
+		return 0, false
+	}
+}
+
+func (g *Gorth) Mean() error {
+	// pops every consecutive numeric element from the top of the stack
+	// and pushes their average, stopping at the first non numeric element
+	sum := 0.0
+	count := 0
+	for len(g.ExecStack) > 0 {
+		val, err := g.resolve(g.ExecStack[len(g.ExecStack)-1])
+		if err != nil {
+			return err
+		}
+
+		num, ok := toFloat(val)
+		if !ok {
+			break
+		}
+
+		g.Pop()
+		sum += num
+		count++
+	}
+
+	if count == 0 {
+		return errors.New("ERROR: no numeric elements on top of the stack to perform MEAN_OP")
+	}
+
+	return g.pushFloat("MEAN_OP", sum/float64(count))
+}
+
+func (g *Gorth) Between() error {
+	// value low high between pushes true when low <= value <= high
+	high, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	low, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	highNum, ok1 := toFloat(high)
+	lowNum, ok2 := toFloat(low)
+	num, ok3 := toFloat(val)
+	if !ok1 || !ok2 || !ok3 {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform BETWEEN_OP on non numeric types")
+	}
+
+	return g.Push(StackElement{Type: Bool, Value: lowNum <= num && num <= highNum})
+}
+
+func (g *Gorth) ClearNums() {
+	// discards numbers from the top of the stack until a non numeric element is reached
+	// an empty stack is left as is
+	for len(g.ExecStack) > 0 {
+		val, err := g.resolve(g.ExecStack[len(g.ExecStack)-1])
+		if err != nil {
+			return
+		}
+
+		if _, ok := toFloat(val); !ok {
+			return
+		}
+
+		g.Pop()
+	}
+}
+
+func (g *Gorth) Len() error {
+	// pops a string or a list and pushes its length, strings are measured in runes
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	switch val.Type {
+	case String:
+		return g.Push(StackElement{Type: Int, Value: utf8.RuneCountInString(val.Value.(string))})
+	case List:
+		return g.Push(StackElement{Type: Int, Value: len(val.Value.([]StackElement))})
+	default:
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform LEN_OP on non string or list types")
+	}
+}
+
+func (g *Gorth) Index() error {
+	// pops an index i and pushes a copy of element i of the list beneath it, leaving the list in place
+	// eg. [ 1 2 3 ] 1 index leaves [ 1 2 3 ] 2
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform INDEX_OP")
+	}
+
+	index, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if index.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: INDEX_OP expects an integer index on top of the stack")
+	}
+
+	items, err := g.peekList("INDEX_OP")
+	if err != nil {
+		return err
+	}
+
+	i := index.Value.(int)
+	if i < 0 || i >= len(items) {
+		return fmt.Errorf("ERROR: INDEX_OP index %d is out of range for a list of %d elements", i, len(items))
+	}
+
+	return g.Push(copyElement(items[i]))
+}
+
+func (g *Gorth) Append() error {
+	// pops a value and a list and pushes a new list with the value at the end
+	// eg. [ 1 2 ] 3 append is [ 1 2 3 ]
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform APPEND_OP")
+	}
+
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	items, err := g.popList("APPEND_OP")
+	if err != nil {
+		return err
+	}
+
+	items = append(append([]StackElement{}, items...), val)
+	return g.Push(copyElement(StackElement{Type: List, Value: items}))
+}
+
+func (g *Gorth) DropDup() error {
+	// drops the top element when it is equal to the element beneath it, using the same
+	// comparison as EQUAL_OP, eg. 1 1 dropdup is 1 and 1 2 dropdup is 1 2
+	// the element beneath is never consumed and nothing is pushed
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform DROPDUP_OP")
+	}
+
+	equal, err := g.equals(g.ExecStack[len(g.ExecStack)-1], g.ExecStack[len(g.ExecStack)-2])
+	if err != nil {
+		return err
+	}
+
+	if equal {
+		_, err = g.Pop()
+	}
+
+	return err
+}
+
+func (g *Gorth) StackCount() error {
+	// pops a value and pushes how many of the remaining elements are equal to it,
+	// using the same comparison as EQUAL_OP. The remaining elements are not consumed
+	val, err := g.Pop()
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, el := range g.ExecStack {
+		equal, err := g.equals(val, el)
+		if err != nil {
+			return err
+		}
+
+		if equal {
+			count++
+		}
+	}
+
+	return g.Push(StackElement{Type: Int, Value: count})
+}
+
+func (g *Gorth) StackList() error {
+	// pushes a deep copied list of the whole stack, bottom to top, leaving the stack in place
+	// identifiers are resolved so the snapshot holds the values the variables had at the time
+	items := make([]StackElement, 0, len(g.ExecStack))
+	for _, el := range g.ExecStack {
+		val, err := g.resolve(el)
+		if err != nil {
+			return err
+		}
+
+		items = append(items, copyElement(val))
+	}
+
+	return g.Push(StackElement{Type: List, Value: items})
+}
+
+func (g *Gorth) Store() error {
+	// pops a register name and a value, and saves the value in that register
+	// eg. 42 "r1" store
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform STORE_OP")
+	}
+
+	name, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if name.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: STORE_OP expects a String register name")
+	}
+
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	g.Registers[name.Value.(string)] = copyElement(val)
+	return nil
+}
+
+func (g *Gorth) Load() error {
+	// pops a register name and pushes the value saved in that register
+	name, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if name.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: LOAD_OP expects a String register name")
+	}
+
+	val, ok := g.Registers[name.Value.(string)]
+	if !ok {
+		return fmt.Errorf("ERROR: register %v has not been set", name.Value)
+	}
+
+	return g.Push(copyElement(val))
+}
+
+func (g *Gorth) SwapRegs() error {
+	// pops two register names and exchanges the values saved in them
+	// eg. "r1" "r2" swapregs
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform SWAPREGS_OP")
+	}
+
+	second, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	first, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if first.Type != String || second.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: SWAPREGS_OP expects String register names")
+	}
+
+	name1, name2 := first.Value.(string), second.Value.(string)
+	val1, ok := g.Registers[name1]
+	if !ok {
+		return fmt.Errorf("ERROR: register %v has not been set", name1)
+	}
+
+	val2, ok := g.Registers[name2]
+	if !ok {
+		return fmt.Errorf("ERROR: register %v has not been set", name2)
+	}
+
+	g.Registers[name1], g.Registers[name2] = val2, val1
+	return nil
+}
+
+func (g *Gorth) IsNaN() error {
+	// checks if the float on top of the stack is NaN
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Float {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform ISNAN_OP on non float types")
+	}
+
+	return g.Push(StackElement{Type: Bool, Value: math.IsNaN(val.Value.(float64))})
+}
+
+func (g *Gorth) IsInf() error {
+	// checks if the float on top of the stack is positive or negative infinity
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Float {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform ISINF_OP on non float types")
+	}
+
+	return g.Push(StackElement{Type: Bool, Value: math.IsInf(val.Value.(float64), 0)})
+}
+
+// readLine reads the next line from Input without its line ending
+func (g *Gorth) readLine(op string) (string, error) {
+	if g.reader == nil || g.readerSource != g.Input {
+		g.reader = bufio.NewReader(g.Input)
+		g.readerSource = g.Input
+	}
+
+	line, err := g.reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", fmt.Errorf("ERROR: no input left to perform %v", op)
+	}
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// parseInput turns a piece of input into the most specific element it can be, an Int, Float
+// or Bool when it parses as one, otherwise a String
+func parseInput(s string) StackElement {
+	if val, err := strconv.Atoi(s); err == nil {
+		return StackElement{Type: Int, Value: val}
+	}
+
+	if val, err := strconv.ParseFloat(s, 64); err == nil {
+		return StackElement{Type: Float, Value: val}
+	}
+
+	if s == "true" || s == "false" {
+		return StackElement{Type: Bool, Value: s == "true"}
+	}
+
+	return StackElement{Type: String, Value: s}
+}
+
+func (g *Gorth) Read() error {
+	// reads a line from Input and pushes it as an Int, Float, Bool or String
+	line, err := g.readLine("READ_OP")
+	if err != nil {
+		return err
+	}
+
+	return g.Push(parseInput(line))
+}
+
+func (g *Gorth) ReadFields() error {
+	// reads a line from Input, pushes each whitespace separated field as an Int, Float, Bool
+	// or String and then the number of fields, eg. 10 3.5 hello true pushes 10 3.5 "hello" true 4
+	line, err := g.readLine("READFIELDS_OP")
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(line)
+	for _, field := range fields {
+		err = g.Push(parseInput(field))
+		if err != nil {
+			return err
+		}
+	}
+
+	return g.Push(StackElement{Type: Int, Value: len(fields)})
+}
+
+func (g *Gorth) Commas() error {
+	// pops a number and pushes it as a string with commas between every group of three digits
+	// eg. 1000000 commas is "1,000,000" and -1234.5 commas is "-1,234.5"
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	var s string
+	switch val.Type {
+	case Int:
+		s = strconv.Itoa(val.Value.(int))
+	case Float:
+		f := val.Value.(float64)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return errors.New("ERROR: cannot perform COMMAS_OP on a non-finite float")
+		}
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	default:
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform COMMAS_OP on non numeric types")
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	// only the whole part of a float is grouped
+	whole, fraction := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		whole, fraction = s[:i], s[i:]
+	}
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return g.Push(StackElement{Type: String, Value: sign + grouped.String() + fraction})
+}
+
+func (g *Gorth) ToInt() error {
+	// pops a number or numeric string and pushes it as an integer
+	// floats are truncated towards zero, eg. 3.9 toint is 3
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	switch val.Type {
+	case Int:
+		return g.Push(val)
+	case Float:
+		f := val.Value.(float64)
+		if math.IsNaN(f) || math.IsInf(f, 0) || f >= math.MaxInt64 || f < math.MinInt64 {
+			return fmt.Errorf("ERROR: cannot convert %v to an integer", f)
+		}
+		return g.Push(StackElement{Type: Int, Value: int(f)})
+	case String:
+		n, err := strconv.Atoi(val.Value.(string))
+		if err != nil {
+			return fmt.Errorf("ERROR: cannot convert %q to an integer", val.Value)
+		}
+		return g.Push(StackElement{Type: Int, Value: n})
+	default:
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform TO_INT_OP on non numeric types")
+	}
+}
+
+func (g *Gorth) ToFloat() error {
+	// pops a number or numeric string and pushes it as a float
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	switch val.Type {
+	case Float:
+		return g.Push(val)
+	case Int:
+		return g.Push(StackElement{Type: Float, Value: float64(val.Value.(int))})
+	case String:
+		f, err := strconv.ParseFloat(val.Value.(string), 64)
+		if err != nil {
+			return fmt.Errorf("ERROR: cannot convert %q to a float", val.Value)
+		}
+		return g.Push(StackElement{Type: Float, Value: f})
+	default:
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform TO_FLOAT_OP on non numeric types")
+	}
+}
+
+func (g *Gorth) ToStr() error {
+	// pops a number or bool and pushes it as a string, strings are passed through
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	switch val.Type {
+	case Int, Float, Bool:
+		return g.Push(StackElement{Type: String, Value: fmt.Sprintf("%v", val.Value)})
+	case String:
+		return g.Push(val)
+	default:
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform TO_STR_OP on %v types", typeMap[val.Type])
+	}
+}
+
+func (g *Gorth) BoolToStr() error {
+	// pops a bool and pushes "true" or "false"
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Bool {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform BOOL2STR_OP on non boolean types")
+	}
+
+	return g.Push(StackElement{Type: String, Value: strconv.FormatBool(val.Value.(bool))})
+}
+
+func (g *Gorth) StrToBool() error {
+	// pops a string and pushes the bool it spells, true and 1 are true, false and 0 are false
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform STR2BOOL_OP on non string types")
+	}
+
+	switch val.Value.(string) {
+	case "true", "1":
+		return g.Push(StackElement{Type: Bool, Value: true})
+	case "false", "0":
+		return g.Push(StackElement{Type: Bool, Value: false})
+	default:
+		return fmt.Errorf("ERROR: cannot convert %q to a bool", val.Value)
+	}
+}
+
+func (g *Gorth) Seed() error {
+	// pops an integer and reseeds Rand with it, so the random operations that follow
+	// give the same results every run
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform SEED_OP on non integer types")
+	}
+
+	g.Rand = rand.New(rand.NewSource(int64(val.Value.(int))))
+	return nil
+}
+
+func (g *Gorth) LShuffle() error {
+	// pops a list and pushes a shuffled copy of it using Rand
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != List {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform LSHUFFLE_OP on non list types")
+	}
+
+	shuffled := copyElement(val)
+	items := shuffled.Value.([]StackElement)
+	g.Rand.Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+
+	return g.Push(shuffled)
+}
+
+func (g *Gorth) Duration() error {
+	// pops a number of seconds and pushes it formatted as a duration string
+	// eg. 3661 duration is "1h1m1s", negative durations get a leading minus
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform DURATION_OP on non integer types")
+	}
+
+	seconds := val.Value.(int)
+	limit := int(math.MaxInt64 / int64(time.Second))
+	if seconds > limit || seconds < -limit {
+		return fmt.Errorf("ERROR: %d seconds is out of range for DURATION_OP", seconds)
+	}
+
+	return g.Push(StackElement{Type: String, Value: (time.Duration(seconds) * time.Second).String()})
+}
+
+func (g *Gorth) DateFmt() error {
+	// pops a go time layout and pushes the current time formatted with it
+	// eg. "2006-01-02" datefmt is today's date
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: DATEFMT_OP expects a string layout on top of the stack")
+	}
+
+	return g.Push(StackElement{Type: String, Value: g.Now().Format(val.Value.(string))})
+}
+
+func (g *Gorth) Pause() error {
+	// pops a number of milliseconds and pauses for that long, eg. 500 sleep
+	if !g.AllowSleep {
+		return errors.New("ERROR: SLEEP_OP is disabled")
+	}
+
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform SLEEP_OP on non integer types")
+	}
+
+	ms := val.Value.(int)
+	if ms < 0 {
+		return errors.New("ERROR: cannot perform SLEEP_OP with a negative duration")
+	}
+
+	if ms > int(math.MaxInt64/int64(time.Millisecond)) {
+		return fmt.Errorf("ERROR: %d milliseconds is out of range for SLEEP_OP", ms)
+	}
+
+	g.Sleep(time.Duration(ms) * time.Millisecond)
+	return nil
+}
+
+func (g *Gorth) DigitSum() error {
+	// pushes the sum of the decimal digits of the top integer, ignoring its sign
+	// eg. 12345 digitsum is 15
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform DIGITSUM_OP on non integer types")
+	}
+
+	n := val.Value.(int)
+	sum := 0
+	for n != 0 {
+		// the remainder takes the sign of n, so flip negative digits rather than negating n which can overflow
+		digit := n % 10
+		if digit < 0 {
+			digit = -digit
+		}
+		sum += digit
+		n /= 10
+	}
+
+	return g.Push(StackElement{Type: Int, Value: sum})
+}
+
+func (g *Gorth) BitAnd() error {
+	// eg. 6 3 & is 2
+	return g.bitwise("BIT_AND_OP", func(a, b int) (int, error) { return a & b, nil })
+}
+
+func (g *Gorth) BitOr() error {
+	return g.bitwise("BIT_OR_OP", func(a, b int) (int, error) { return a | b, nil })
+}
+
+func (g *Gorth) BitXor() error {
+	return g.bitwise("BIT_XOR_OP", func(a, b int) (int, error) { return a ^ b, nil })
+}
+
+func (g *Gorth) ShiftLeft() error {
+	// shifts the second value left by the top value, eg. 1 4 << is 16
+	return g.bitwise("SHIFT_LEFT_OP", func(a, b int) (int, error) {
+		if b < 0 {
+			return 0, errors.New("ERROR: cannot perform SHIFT_LEFT_OP with a negative shift count")
+		}
+		return a << b, nil
+	})
+}
+
+func (g *Gorth) ShiftRight() error {
+	// shifts the second value right by the top value, keeping its sign
+	return g.bitwise("SHIFT_RIGHT_OP", func(a, b int) (int, error) {
+		if b < 0 {
+			return 0, errors.New("ERROR: cannot perform SHIFT_RIGHT_OP with a negative shift count")
+		}
+		return a >> b, nil
+	})
+}
+
+// bitwise pops two integers and pushes f of the second and the top
+func (g *Gorth) bitwise(op string, f func(a, b int) (int, error)) error {
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform %v", op)
+	}
+
+	b, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	a, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if a.Type != Int || b.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform %v on non integer types", op)
+	}
+
+	result, err := f(a.Value.(int), b.Value.(int))
+	if err != nil {
+		return err
+	}
+
+	return g.Push(StackElement{Type: Int, Value: result})
+}
+
+func (g *Gorth) RevDigits() error {
+	// pushes the top integer with its decimal digits reversed, keeping its sign
+	// eg. 12345 revdigits is 54321 and -120 revdigits is -21
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform REVDIGITS_OP on non integer types")
+	}
+
+	n := val.Value.(int)
+	reversed := 0
+	for n != 0 {
+		// the remainder has the sign of n, so reversed keeps the sign as it grows
+		digit := n % 10
+		if (digit > 0 && reversed > (math.MaxInt-digit)/10) || (digit < 0 && reversed < (math.MinInt-digit)/10) {
+			return errors.New("ERROR: integer overflow in REVDIGITS_OP")
+		}
+		reversed = reversed*10 + digit
+		n /= 10
+	}
+
+	return g.Push(StackElement{Type: Int, Value: reversed})
+}
+
+func (g *Gorth) Lcp() error {
+	// pops two strings and pushes the length in runes of their longest common prefix
+	// eg. "foobar" "foobaz" lcp is 5
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform LCP_OP")
+	}
+
+	val1, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	val2, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val1.Type != String || val2.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform LCP_OP on non string types")
+	}
+
+	runes1 := []rune(val1.Value.(string))
+	runes2 := []rune(val2.Value.(string))
+	n := 0
+	for n < len(runes1) && n < len(runes2) && runes1[n] == runes2[n] {
+		n++
+	}
+
+	return g.Push(StackElement{Type: Int, Value: n})
+}
+
+func (g *Gorth) Interleave() error {
+	// pops two lists and pushes their elements alternately, then whatever is left of the longer one
+	// eg. [ 1 2 3 ] [ 4 5 6 ] interleave is [ 1 4 2 5 3 6 ]
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform INTERLEAVE_OP")
+	}
+
+	second, err := g.popList("INTERLEAVE_OP")
+	if err != nil {
+		return err
+	}
+
+	first, err := g.popList("INTERLEAVE_OP")
+	if err != nil {
+		return err
+	}
+
+	items := make([]StackElement, 0, len(first)+len(second))
+	for i := 0; i < len(first) || i < len(second); i++ {
+		if i < len(first) {
+			items = append(items, first[i])
+		}
+		if i < len(second) {
+			items = append(items, second[i])
+		}
+	}
+
+	return g.Push(copyElement(StackElement{Type: List, Value: items}))
+}
+
+func (g *Gorth) Hamming() error {
+	// pops two strings of the same length and pushes how many positions they differ at
+	// eg. "karolin" "kathrin" hamming is 3
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform HAMMING_OP")
+	}
+
+	val1, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	val2, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val1.Type != String || val2.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform HAMMING_OP on non string types")
+	}
+
+	runes1 := []rune(val2.Value.(string))
+	runes2 := []rune(val1.Value.(string))
+	if len(runes1) != len(runes2) {
+		return fmt.Errorf("ERROR: HAMMING_OP expects strings of the same length, but got %d and %d", len(runes1), len(runes2))
+	}
+
+	distance := 0
+	for i := range runes1 {
+		if runes1[i] != runes2[i] {
+			distance++
+		}
+	}
+
+	return g.Push(StackElement{Type: Int, Value: distance})
+}
+
+func (g *Gorth) Transpose() error {
+	// pops a list of equal length lists and pushes it with rows and columns swapped
+	// eg. [ [ 1 2 ] [ 3 4 ] ] transpose is [ [ 1 3 ] [ 2 4 ] ]
+	rows, err := g.popList("TRANSPOSE_OP")
+	if err != nil {
+		return err
+	}
+
+	width := 0
+	for i, row := range rows {
+		if row.Type != List {
+			return wrapError(ErrTypeMismatch, "ERROR: TRANSPOSE_OP expects a list of lists")
+		}
+
+		if i == 0 {
+			width = len(row.Value.([]StackElement))
+		} else if len(row.Value.([]StackElement)) != width {
+			return fmt.Errorf("ERROR: TRANSPOSE_OP expects lists of the same length, but row %d has %d elements instead of %d", i, len(row.Value.([]StackElement)), width)
+		}
+	}
+
+	columns := make([]StackElement, width)
+	for j := range columns {
+		column := make([]StackElement, len(rows))
+		for i, row := range rows {
+			column[i] = row.Value.([]StackElement)[j]
+		}
+		columns[j] = StackElement{Type: List, Value: column}
+	}
+
+	return g.Push(copyElement(StackElement{Type: List, Value: columns}))
+}
+
+func (g *Gorth) LClamp() error {
+	// list low high lclamp pushes the list with each number clamped to low <= n <= high
+	// eg. [ -1 5 11 ] 0 10 lclamp is [ 0 5 10 ], every element keeps its type
+	if len(g.ExecStack) < 3 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 3 elements need to be on stack to perform LCLAMP_OP")
+	}
+
+	high, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	low, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	highNum, ok1 := toFloat(high)
+	lowNum, ok2 := toFloat(low)
+	if !ok1 || !ok2 {
+		return wrapError(ErrTypeMismatch, "ERROR: LCLAMP_OP expects numeric bounds")
+	}
+
+	if lowNum > highNum {
+		return errors.New("ERROR: LCLAMP_OP expects the low bound to be at most the high bound")
+	}
+
+	items, err := g.popList("LCLAMP_OP")
+	if err != nil {
+		return err
+	}
+
+	clamped := make([]StackElement, len(items))
+	for i, item := range items {
+		num, ok := toFloat(item)
+		if !ok {
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform LCLAMP_OP on non numeric elements")
+		}
+
+		switch {
+		case num < lowNum:
+			clamped[i] = clampTo(item, low, math.Ceil)
+		case num > highNum:
+			clamped[i] = clampTo(item, high, math.Floor)
+		default:
+			clamped[i] = item
+		}
+	}
+
+	return g.Push(StackElement{Type: List, Value: clamped})
+}
+
+// clampTo returns bound in place of item with the type of item, so Int and Float are kept per
+// element. A float bound is rounded with round for an int item so the result stays in the range
+func clampTo(item, bound StackElement, round func(float64) float64) StackElement {
+	value, _ := toFloat(bound)
+	if item.Type == Float {
+		return StackElement{Type: Float, Value: value}
+	}
+
+	return StackElement{Type: Int, Value: int(round(value))}
+}
+
+func (g *Gorth) SaveStack() error {
+	// pops a filename and writes the rest of the stack to it in the MarshalState format, with
+	// variables replaced by their values
+	// eg. "stack.json" savestack, loadstack reads it back
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: SAVE_STACK_OP expects a filename on top of the stack")
+	}
+
+	// identifiers are resolved like stacklist does, the variables they name do not exist
+	// in the run that loads the file
+	stack := make([]StackElement, 0, len(g.ExecStack))
+	for _, el := range g.ExecStack {
+		resolved, err := g.resolve(el)
+		if err != nil {
+			return err
+		}
+
+		stack = append(stack, resolved)
+	}
+
+	data, err := json.Marshal(savedState{Stack: stack})
+	if err != nil {
+		return fmt.Errorf("ERROR: cannot perform SAVE_STACK_OP: %v", err)
+	}
+
+	err = os.WriteFile(val.Value.(string), data, 0644)
+	if err != nil {
+		return fmt.Errorf("ERROR: cannot perform SAVE_STACK_OP: %v", err)
+	}
+
+	return nil
+}
+
+func (g *Gorth) LoadStack() error {
+	// pops a filename and replaces the stack with the one saved in it by savestack
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: LOAD_STACK_OP expects a filename on top of the stack")
+	}
+
+	data, err := os.ReadFile(val.Value.(string))
+	if err != nil {
+		return fmt.Errorf("ERROR: cannot perform LOAD_STACK_OP: %v", err)
+	}
+
+	var state savedState
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return fmt.Errorf("ERROR: cannot perform LOAD_STACK_OP: %v", err)
+	}
+
+	if len(state.Stack) > g.MaxStackSize {
+		return wrapError(ErrStackOverflow, "ERROR: stack overflow")
+	}
+
+	g.ExecStack = state.Stack
+	if g.ExecStack == nil {
+		g.ExecStack = []StackElement{}
+	}
+	return nil
+}
+
+func (g *Gorth) CumSum() error {
+	// pops a numeric list and pushes its running totals, eg. [ 1 2 3 ] cumsum is [ 1 3 6 ]
+	// the totals stay ints until the first float, from then on they are floats
+	items, err := g.popList("CUMSUM_OP")
+	if err != nil {
+		return err
+	}
+
+	sums := make([]StackElement, len(items))
+	intSum, floatSum := 0, 0.0
+	isFloat := false
+	for i, item := range items {
+		if item.Type == Int && !isFloat {
+			intSum += item.Value.(int)
+			sums[i] = StackElement{Type: Int, Value: intSum}
+			continue
+		}
+
+		num, ok := toFloat(item)
+		if !ok {
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform CUMSUM_OP on non numeric elements")
+		}
+		if !isFloat {
+			floatSum = float64(intSum)
+			isFloat = true
+		}
+		floatSum += num
+		sums[i] = StackElement{Type: Float, Value: floatSum}
+	}
+
+	return g.Push(StackElement{Type: List, Value: sums})
+}
+
+func (g *Gorth) LScale() error {
+	// pops a factor and a numeric list, pushing the list with every element multiplied by the factor
+	// eg. [ 1 2 3 ] 2 lscale is [ 2 4 6 ], an element only stays an int if it and the factor are ints
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform LSCALE_OP")
+	}
+
+	factor, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	factorNum, ok := toFloat(factor)
+	if !ok {
+		return wrapError(ErrTypeMismatch, "ERROR: LSCALE_OP expects a numeric factor on top of the stack")
+	}
+
+	items, err := g.popList("LSCALE_OP")
+	if err != nil {
+		return err
+	}
+
+	scaled := make([]StackElement, len(items))
+	for i, item := range items {
+		if item.Type == Int && factor.Type == Int {
+			scaled[i] = StackElement{Type: Int, Value: item.Value.(int) * factor.Value.(int)}
+			continue
+		}
+
+		num, ok := toFloat(item)
+		if !ok {
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform LSCALE_OP on non numeric elements")
+		}
+		scaled[i] = StackElement{Type: Float, Value: num * factorNum}
+	}
+
+	return g.Push(StackElement{Type: List, Value: scaled})
+}
+
+func (g *Gorth) StdDev() error {
+	// pops a numeric list and pushes its population standard deviation as a float
+	// eg. [ 2 4 4 4 5 5 7 9 ] stddev is 2.0
+	items, err := g.popList("STDDEV_OP")
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return errors.New("ERROR: cannot perform STDDEV_OP on an empty list")
+	}
+
+	nums := make([]float64, len(items))
+	sum := 0.0
+	for i, item := range items {
+		num, ok := toFloat(item)
+		if !ok {
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform STDDEV_OP on non numeric elements")
+		}
+		nums[i] = num
+		sum += num
+	}
+
+	mean := sum / float64(len(nums))
+	variance := 0.0
+	for _, num := range nums {
+		variance += (num - mean) * (num - mean)
+	}
+
+	return g.pushFloat("STDDEV_OP", math.Sqrt(variance/float64(len(nums))))
+}
+
+func (g *Gorth) Substr() error {
+	// string start length substr pushes length runes of the string from start
+	// eg. "hello" 1 3 substr is "ell", a range past either end of the string is an error
+	if len(g.ExecStack) < 3 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 3 elements need to be on stack to perform SUBSTR_OP")
+	}
+
+	length, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	start, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	str, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if length.Type != Int || start.Type != Int || str.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: SUBSTR_OP expects a string, a start index and a length")
+	}
+
+	runes := []rune(str.Value.(string))
+	i, n := start.Value.(int), length.Value.(int)
+	if i < 0 || n < 0 || i > len(runes) || n > len(runes)-i {
+		return fmt.Errorf("ERROR: SUBSTR_OP range %d to %d is out of range for a string of %d runes", i, i+n, len(runes))
+	}
+
+	return g.Push(StackElement{Type: String, Value: string(runes[i : i+n])})
+}
+
+func (g *Gorth) Histogram() error {
+	// pops a bucket width and a numeric list, pushing [ start count ] pairs for every bucket that has
+	// elements in it, sorted by start, eg. [ 1 2 5 11 ] 5 histogram is [ [ 0 2 ] [ 5 1 ] [ 10 1 ] ]
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform HISTOGRAM_OP")
+	}
+
+	width, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if width.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: HISTOGRAM_OP expects an integer bucket width on top of the stack")
+	}
+
+	w := width.Value.(int)
+	if w <= 0 {
+		return errors.New("ERROR: HISTOGRAM_OP expects a positive bucket width")
+	}
+
+	items, err := g.popList("HISTOGRAM_OP")
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[int]int)
+	for _, item := range items {
+		num, ok := toFloat(item)
+		if !ok {
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform HISTOGRAM_OP on non numeric elements")
+		}
+
+		// floor so negative numbers land in the bucket below zero rather than the one above
+		counts[int(math.Floor(num/float64(w)))*w]++
+	}
+
+	starts := make([]int, 0, len(counts))
+	for start := range counts {
+		starts = append(starts, start)
+	}
+	sort.Ints(starts)
+
+	buckets := make([]StackElement, len(starts))
+	for i, start := range starts {
+		buckets[i] = StackElement{Type: List, Value: []StackElement{{Type: Int, Value: start}, {Type: Int, Value: counts[start]}}}
+	}
+
+	return g.Push(StackElement{Type: List, Value: buckets})
+}
+
+func (g *Gorth) Upper() error {
+	// eg. "Hello" upper is "HELLO"
+	return g.mapString("UPPER_OP", strings.ToUpper)
+}
+
+func (g *Gorth) Lower() error {
+	// eg. "Hello" lower is "hello"
+	return g.mapString("LOWER_OP", strings.ToLower)
+}
+
+// mapString pops a string, looking up variables, and pushes f of it
+func (g *Gorth) mapString(op string, f func(string) string) error {
+	val, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if val.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform %v on non string types", op)
+	}
+
+	return g.Push(StackElement{Type: String, Value: f(val.Value.(string))})
+}
+
+func (g *Gorth) Enumerate() error {
+	// pops a list and pushes a list of [ index element ] pairs
+	// eg. [ "a" "b" ] enumerate is [ [ 0 "a" ] [ 1 "b" ] ]
+	items, err := g.popList("ENUMERATE_OP")
+	if err != nil {
+		return err
+	}
+
+	pairs := make([]StackElement, len(items))
+	for i, item := range items {
+		pairs[i] = StackElement{Type: List, Value: []StackElement{{Type: Int, Value: i}, item}}
+	}
+
+	return g.Push(copyElement(StackElement{Type: List, Value: pairs}))
+}
+
+func (g *Gorth) Split() error {
+	// pops a delimiter and a string, pushing a list of the parts between the delimiters
+	// eg. "a,b,c" "," split is [ "a" "b" "c" ], an empty delimiter splits into characters
+	// and an empty string gives an empty list
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform SPLIT_OP")
+	}
+
+	delimiter, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	source, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if delimiter.Type != String || source.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform SPLIT_OP on non string types")
+	}
+
+	parts := []StackElement{}
+	if source.Value.(string) != "" {
+		for _, part := range strings.Split(source.Value.(string), delimiter.Value.(string)) {
+			parts = append(parts, StackElement{Type: String, Value: part})
+		}
+	}
+
+	return g.Push(StackElement{Type: List, Value: parts})
+}
+
+func (g *Gorth) Chunk() error {
+	// pops a size n and a list, pushing the list cut into sublists of n elements, the last one may be shorter
+	// eg. [ 1 2 3 4 5 ] 2 chunk is [ [ 1 2 ] [ 3 4 ] [ 5 ] ]
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform CHUNK_OP")
+	}
+
+	size, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if size.Type != Int {
+		return wrapError(ErrTypeMismatch, "ERROR: CHUNK_OP expects an integer size on top of the stack")
+	}
+
+	n := size.Value.(int)
+	if n <= 0 {
+		return errors.New("ERROR: CHUNK_OP expects a positive size")
+	}
+
+	items, err := g.popList("CHUNK_OP")
+	if err != nil {
+		return err
+	}
+
+	chunks := []StackElement{}
+	for start := 0; start < len(items); start += n {
+		end := start + n
+		if end > len(items) {
+			end = len(items)
+		}
+		chunks = append(chunks, StackElement{Type: List, Value: items[start:end]})
+	}
+
+	return g.Push(copyElement(StackElement{Type: List, Value: chunks}))
+}
+
+func (g *Gorth) Join() error {
+	// pops a separator and a list of strings, pushing the strings joined with the separator
+	// eg. [ "a" "b" "c" ] "," join is "a,b,c"
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform JOIN_OP")
+	}
+
+	separator, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if separator.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: JOIN_OP expects a string separator on top of the stack")
+	}
+
+	items, err := g.popList("JOIN_OP")
+	if err != nil {
+		return err
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		if item.Type != String {
+			return wrapError(ErrTypeMismatch, "ERROR: cannot perform JOIN_OP on non string elements")
+		}
+		parts[i] = item.Value.(string)
+	}
+
+	return g.Push(StackElement{Type: String, Value: strings.Join(parts, separator.Value.(string))})
+}
+
+func (g *Gorth) LDiff() error {
+	// eg. [ 1 2 3 ] [ 2 ] ldiff is [ 1 3 ]
+	return g.setOperation("LDIFF_OP", func(first, second []StackElement) []StackElement {
+		return first
+	}, func(inSecond bool) bool { return !inSecond })
+}
+
+func (g *Gorth) LUnion() error {
+	// eg. [ 1 2 ] [ 2 3 ] lunion is [ 1 2 3 ]
+	return g.setOperation("LUNION_OP", func(first, second []StackElement) []StackElement {
+		return append(append([]StackElement{}, first...), second...)
+	}, func(inSecond bool) bool { return true })
+}
+
+func (g *Gorth) LIntersect() error {
+	// eg. [ 1 2 3 ] [ 3 2 ] lintersect is [ 2 3 ]
+	return g.setOperation("LINTERSECT_OP", func(first, second []StackElement) []StackElement {
+		return first
+	}, func(inSecond bool) bool { return inSecond })
+}
+
+// setOperation pops two lists and pushes the candidates picked from them that keep accepts, given
+// whether each one is in the second list. Elements are compared with == and only the first of
+// equal elements is kept, so the result is in first seen order without duplicates
+func (g *Gorth) setOperation(op string, candidates func(first, second []StackElement) []StackElement, keep func(inSecond bool) bool) error {
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform %v", op)
+	}
+
+	second, err := g.popList(op)
+	if err != nil {
+		return err
+	}
+
+	first, err := g.popList(op)
+	if err != nil {
+		return err
+	}
+
+	result := []StackElement{}
+	for _, item := range candidates(first, second) {
+		seen, err := g.listContains(result, item)
+		if err != nil {
+			return err
+		}
+		if seen {
+			continue
+		}
+
+		inSecond, err := g.listContains(second, item)
+		if err != nil {
+			return err
+		}
+		if keep(inSecond) {
+			result = append(result, item)
+		}
+	}
+
+	return g.Push(copyElement(StackElement{Type: List, Value: result}))
+}
+
+// listContains reports whether any element of items is == to val
+func (g *Gorth) listContains(items []StackElement, val StackElement) (bool, error) {
+	for _, item := range items {
+		equal, err := g.equals(item, val)
+		if err != nil || equal {
+			return equal, err
+		}
+	}
+	return false, nil
+}
+
+func (g *Gorth) Contains() error {
+	// pops a needle and a haystack string and pushes whether the haystack contains the needle
+	// eg. "hello world" "world" contains is true
+	if len(g.ExecStack) < 2 {
+		return wrapError(ErrStackEmpty, "ERROR: at least 2 elements need to be on stack to perform CONTAINS_OP")
+	}
+
+	needle, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	haystack, err := g.popValue()
+	if err != nil {
+		return err
+	}
+
+	if needle.Type != String || haystack.Type != String {
+		return wrapError(ErrTypeMismatch, "ERROR: cannot perform CONTAINS_OP on non string types")
+	}
+
+	return g.Push(StackElement{Type: Bool, Value: strings.Contains(haystack.Value.(string), needle.Value.(string))})
+}
+
+func (g *Gorth) ToMap() error {
+	// pops a list of [ key value ] pairs and pushes them as a map, a later pair replaces an
+	// earlier one with the same key
+	// eg. [ [ "a" 1 ] [ "b" 2 ] ] tomap
+	items, err := g.popList("TOMAP_OP")
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string]StackElement, len(items))
+	for _, item := range items {
+		if item.Type != List || len(item.Value.([]StackElement)) != 2 {
+			return fmt.Errorf("ERROR: TOMAP_OP expects a list of [ key value ] pairs, but got %v", item)
+		}
+
+		pair := item.Value.([]StackElement)
+
+		if pair[0].Type != String {
+			return wrapError(ErrTypeMismatch, "ERROR: TOMAP_OP keys must be strings, but got %v", pair[0])
+		}
+
+		entries[pair[0].Value.(string)] = copyElement(pair[1])
+	}
+
+	return g.Push(StackElement{Type: Map, Value: entries})
+}
+
+func (g *Gorth) MKeys() error {
+	// pops a map and pushes a list of its keys in sorted order
+	// eg. [ [ "b" 2 ] [ "a" 1 ] ] tomap mkeys is [ "a" "b" ]
+	entries, err := g.popMap("MKEYS_OP")
+	if err != nil {
+		return err
+	}
+
+	keys := []StackElement{}
+	for _, key := range sortedKeys(entries) {
+		keys = append(keys, StackElement{Type: String, Value: key})
+	}
+
+	return g.Push(StackElement{Type: List, Value: keys})
+}
+
+func (g *Gorth) MValues() error {
+	// pops a map and pushes a list of its values, in the sorted order of their keys
+	// eg. [ [ "b" 2 ] [ "a" 1 ] ] tomap mvalues is [ 1 2 ]
+	entries, err := g.popMap("MVALUES_OP")
+	if err != nil {
+		return err
+	}
+
+	values := []StackElement{}
+	for _, key := range sortedKeys(entries) {
+		values = append(values, copyElement(entries[key]))
+	}
+
+	return g.Push(StackElement{Type: List, Value: values})
+}
+
+func (g *Gorth) popMap(op string) (map[string]StackElement, error) {
+	val, err := g.popValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if val.Type != Map {
+		return nil, wrapError(ErrTypeMismatch, "ERROR: cannot perform %v on non map types", op)
+	}
+
+	return val.Value.(map[string]StackElement), nil
+}
+
+// sortedKeys returns the keys of a map in sorted order, Go maps have no order of their own
+// so this keeps mkeys and mvalues deterministic and lined up with each other
+func sortedKeys(entries map[string]StackElement) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (g *Gorth) Mode() error {
+	// pops a list and pushes its most frequent element, compared with ==, the first seen wins a tie
+	// eg. [ 1 2 2 3 ] mode is 2
+	items, err := g.popList("MODE_OP")
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return errors.New("ERROR: cannot perform MODE_OP on an empty list")
+	}
+
+	// distinct elements in first seen order, with how many times each one occurs
+	var distinct []StackElement
+	var counts []int
+	for _, item := range items {
+		found := false
+		for i := range distinct {
+			equal, err := g.equals(distinct[i], item)
+			if err != nil {
+				return err
+			}
+			if equal {
+				counts[i]++
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			distinct = append(distinct, item)
+			counts = append(counts, 1)
+		}
+	}
+
+	mode := 0
+	for i := range counts {
+		if counts[i] > counts[mode] {
+			mode = i
+		}
+	}
+
+	return g.Push(copyElement(distinct[mode]))
+}
+
+func (g *Gorth) PrintStack() {
+	fmt.Fprintf(g.Out, "Program stack: %v\n", g.ExecStack)
+}
+
+// Call runs the body of a procedure defined with def name ... end
+func (g *Gorth) Call(name string) error {
+	body, ok := g.Procedures[name]
+	if !ok {
+		return fmt.Errorf("ERROR: procedure %v has not been defined", name)
+	}
+
+	if g.callDepth >= g.MaxCallDepth {
+		return fmt.Errorf("ERROR: maximum call depth of %d exceeded", g.MaxCallDepth)
+	}
+
+	g.callDepth++
+	defer func() {
+		g.callDepth--
+	}()
+
+	err := g.execute(body)
+	if errors.Is(err, errReturn) {
+		return nil
+	}
+
+	return err
+}
+
+// runQuotation runs the body of a quotation against the stack, a return inside it
+// only stops the quotation
+func (g *Gorth) runQuotation(quotation StackElement) error {
+	err := g.execute(quotation.Value.([]StackElement))
+	if errors.Is(err, errReturn) {
+		return nil
+	}
+
+	return err
+}
+
+// matchBlocks pairs up the block keywords of a program. Every if maps to its else (or end
+// when there is no else), every else and do to its end, and the end of a loop back to its while
+func matchBlocks(program []StackElement) (map[int]int, error) {
+	jumps := make(map[int]int)
+	// loops maps the position of a do to the position of its while
+	loops := make(map[int]int)
+	var open []int
+
+	for i, op := range program {
+		if op.Type != KeyWord {
+			continue
+		}
+
+		switch op.Value {
+		case "if", "while", "scope":
+			open = append(open, i)
+		case "else":
+			if len(open) < 1 || program[open[len(open)-1]].Value != "if" {
+				return nil, errors.New("ERROR: else without a matching if")
+			}
+			jumps[open[len(open)-1]] = i
+			open[len(open)-1] = i
+		case "do":
+			if len(open) < 1 || program[open[len(open)-1]].Value != "while" {
+				return nil, errors.New("ERROR: do without a matching while")
+			}
+			loops[i] = open[len(open)-1]
+			open[len(open)-1] = i
+		case "end":
+			if len(open) < 1 {
+				return nil, errors.New("ERROR: end without a matching if, while or scope")
+			}
+
+			top := open[len(open)-1]
+			if program[top].Value == "while" {
+				return nil, errors.New("ERROR: while without a matching do")
+			}
+
+			jumps[top] = i
+			if program[top].Value == "do" {
+				jumps[i] = loops[top]
+			}
+			// the end of a scope points back at it so ExecuteProgram knows to close the scope
+			if program[top].Value == "scope" {
+				jumps[i] = top
+			}
+			open = open[:len(open)-1]
+		}
+	}
+
+	if len(open) > 0 {
+		return nil, fmt.Errorf("ERROR: %v without a matching end", program[open[len(open)-1]].Value)
+	}
+
+	return jumps, nil
+}
+
+// popCondition pops the Bool that decides whether a block runs
+func (g *Gorth) popCondition(keyword string) (bool, error) {
+	val, err := g.popValue()
+	if err != nil {
+		return false, err
+	}
+
+	if val.Type != Bool {
+		return false, wrapError(ErrTypeMismatch, "ERROR: %v expects a Bool on top of the stack", keyword)
+	}
+
+	return val.Value.(bool), nil
+}
+
+func (g *Gorth) ExecuteProgram(program []StackElement) (err error) {
+	g.instructionCount = 0
+	g.opCounts = make(map[Operation]int)
+	g.stackHighWater = len(g.ExecStack)
+
+	start := time.Now()
+	defer func() {
+		g.duration = time.Since(start)
+	}()
+
+	// operators assert on the go type of their operands, so a malformed element
+	// would otherwise crash the whole process instead of failing the program
+	g.current = StackElement{}
+	g.position = 0
+	g.callDepth = 0
+	defer func() {
+		if r := recover(); r != nil {
+			gorthErr := &GorthError{Kind: InternalError, Element: g.current, Position: g.position, StackDepth: len(g.ExecStack), Message: fmt.Sprint(r)}
+			if g.current.Type == Operator {
+				gorthErr.Operation, gorthErr.HasOperation = g.current.Value.(Operation)
+			}
+			err = gorthErr
+		}
+	}()
+
+	// a return at the top level stops the program like reaching its end
+	err = g.execute(program)
+	if err != nil && !errors.Is(err, errReturn) {
+		return err
+	}
+
+	if g.StrictMode {
+		if len(g.ExecStack) > 0 {
+			return fmt.Errorf("ERROR: unconsumed elements remain on the stack\n\t%v", g.ExecStack)
+		}
+	}
+
+	if g.DebugMode {
+		fmt.Fprintf(g.Out, "Program stack at end of execution\n\t%v\n", g.ExecStack)
+	}
+
+	return nil
+}
+
+// execute runs a sequence of tokens, either a whole program or the body of a procedure
+func (g *Gorth) execute(program []StackElement) error {
+	jumps, err := matchBlocks(program)
+	if err != nil {
+		return err
+	}
+	// iterations counts how many times the loop whose do is at a given position has run
+	iterations := make(map[int]int)
+
+	// scopes opened here are closed even if a return or an error leaves before their end
+	openScopes := len(g.scopes)
+	defer func() {
+		g.scopes = g.scopes[:openScopes]
+	}()
+
+	for i := 0; i < len(program); i++ {
+		op := program[i]
+		g.current = op
+		g.position = i
+
+		if g.DebugMode {
+			fmt.Fprintln(g.Out, "Current operation: "+fmt.Sprintf("%v", op.Type == Operator))
+			fmt.Fprintln(g.Out, "Current Stack: ", g.ExecStack)
+		}
+
+		g.instructionCount++
+		// the instruction count doubles as the step counter, it covers procedure and quotation bodies too
+		if g.MaxSteps > 0 && g.instructionCount > g.MaxSteps {
+			return errors.New("ERROR: step limit exceeded")
+		}
+
+		if op.Type == Operator {
+			if g.DisabledOps[op.Value.(Operation)] {
+				return disabledError(op.Value.(Operation))
+			}
+			g.opCounts[op.Value.(Operation)]++
+
+			switch op.Value {
+			case ADD_OP:
+				err := g.Add()
+				if err != nil {
+					return err
+				}
+			case SUB_OP:
+				err := g.Sub()
+				if err != nil {
+					return err
+				}
+			case MUL_OP:
+				err := g.Mul()
+				if err != nil {
+					return err
+				}
+			case DIV_OP:
+				err := g.Div()
+				if err != nil {
+					return err
+				}
+			case MOD_OP:
+				err := g.Mod()
+				if err != nil {
+					return err
+				}
+			case EXP_OP:
+				err := g.Exp()
+				if err != nil {
+					return err
+				}
+			case INC_OP:
+				err := g.Inc()
+				if err != nil {
+					return err
+				}
+			case DEC_OP:
+				err := g.Dec()
+				if err != nil {
+					return err
+				}
+			case NEG_OP:
+				err := g.Neg()
+				if err != nil {
+					return err
+				}
+			case SWAP_OP:
+				err := g.Swap()
+				if err != nil {
+					return err
+				}
+			case DUP_OP:
+				err := g.Dup()
+				if err != nil {
+					return err
+				}
+			case DROP_OP:
+				err := g.Drop()
+				if err != nil {
+					return err
+				}
+			case DUMP_OP:
+				err := g.Dump()
+				if err != nil {
+					return err
+				}
 			case PRINT_OP:
 				err := g.Print()
 				if err != nil {
 					return err
 				}
-			case AND_OP:
-				err := g.And()
+			case AND_OP:
+				err := g.And()
+				if err != nil {
+					return err
+				}
+			case OR_OP:
+				err := g.Or()
+				if err != nil {
+					return err
+				}
+			case NOT_OP:
+				err := g.Not()
+				if err != nil {
+					return err
+				}
+			case EQUAL_OP:
+				err := g.Equal()
+				if err != nil {
+					return err
+				}
+			case NOT_EQUAL_OP:
+				err := g.NotEqual()
+				if err != nil {
+					return err
+				}
+			case EQUAL_TYP_OP:
+				err := g.EqualType()
+				if err != nil {
+					return err
+				}
+			case GT_THAN_OP:
+				err := g.GreaterThan()
+				if err != nil {
+					return err
+				}
+			case LS_THAN_OP:
+				err := g.LessThan()
+				if err != nil {
+					return err
+				}
+			case GT_THAN_EQ_OP:
+				err := g.GreaterThanEqual()
+				if err != nil {
+					return err
+				}
+			case LS_THAN_EQ_OP:
+				err := g.LessThanEqual()
+				if err != nil {
+					return err
+				}
+			case ROT_OP:
+				err := g.Rot()
+				if err != nil {
+					return err
+				}
+			case VAR_ASSIGN_OP:
+				err := g.VarAssign()
+				if err != nil {
+					return err
+				}
+			case FIB_OP:
+				err := g.Fib()
+				if err != nil {
+					return err
+				}
+			case PRIME_OP:
+				err := g.Prime()
+				if err != nil {
+					return err
+				}
+			case REVBITS_OP:
+				err := g.RevBits()
+				if err != nil {
+					return err
+				}
+			case POPCOUNT_OP:
+				err := g.PopCount()
+				if err != nil {
+					return err
+				}
+			case BSWAP_OP:
+				err := g.ByteSwap()
+				if err != nil {
+					return err
+				}
+			case PAIR_OP:
+				err := g.Pair()
+				if err != nil {
+					return err
+				}
+			case UNPAIR_OP:
+				err := g.Unpair()
+				if err != nil {
+					return err
+				}
+			case PICK_OP:
+				err := g.Pick()
+				if err != nil {
+					return err
+				}
+			case GOVTYPE_OP:
+				err := g.GoValueType()
+				if err != nil {
+					return err
+				}
+			case ROLL_OP:
+				err := g.Roll()
+				if err != nil {
+					return err
+				}
+			case ROT_BACK_OP:
+				err := g.RotBack()
+				if err != nil {
+					return err
+				}
+			case ASSERT_EQ_OP:
+				err := g.AssertEqual()
+				if err != nil {
+					return err
+				}
+			case ASSERT_MSG_OP:
+				err := g.AssertMessage()
+				if err != nil {
+					return err
+				}
+			case FILL_OP:
+				err := g.Fill()
+				if err != nil {
+					return err
+				}
+			case MEAN_OP:
+				err := g.Mean()
+				if err != nil {
+					return err
+				}
+			case BETWEEN_OP:
+				err := g.Between()
+				if err != nil {
+					return err
+				}
+			case CLEARNUMS_OP:
+				g.ClearNums()
+			case STORE_OP:
+				err := g.Store()
+				if err != nil {
+					return err
+				}
+			case LOAD_OP:
+				err := g.Load()
+				if err != nil {
+					return err
+				}
+			case SWAPREGS_OP:
+				err := g.SwapRegs()
+				if err != nil {
+					return err
+				}
+			case ISNAN_OP:
+				err := g.IsNaN()
+				if err != nil {
+					return err
+				}
+			case ISINF_OP:
+				err := g.IsInf()
+				if err != nil {
+					return err
+				}
+			case READ_OP:
+				err := g.Read()
+				if err != nil {
+					return err
+				}
+			case HEAD_OP:
+				err := g.Head()
+				if err != nil {
+					return err
+				}
+			case LAST_OP:
+				err := g.Last()
+				if err != nil {
+					return err
+				}
+			case TAIL_OP:
+				err := g.Tail()
+				if err != nil {
+					return err
+				}
+			case CONS_OP:
+				err := g.Cons()
+				if err != nil {
+					return err
+				}
+			case DROPDUP_OP:
+				err := g.DropDup()
+				if err != nil {
+					return err
+				}
+			case COMMAS_OP:
+				err := g.Commas()
+				if err != nil {
+					return err
+				}
+			case STACKCOUNT_OP:
+				err := g.StackCount()
+				if err != nil {
+					return err
+				}
+			case TO_INT_OP:
+				err := g.ToInt()
+				if err != nil {
+					return err
+				}
+			case STACKLIST_OP:
+				err := g.StackList()
+				if err != nil {
+					return err
+				}
+			case TO_FLOAT_OP:
+				err := g.ToFloat()
+				if err != nil {
+					return err
+				}
+			case SEED_OP:
+				err := g.Seed()
+				if err != nil {
+					return err
+				}
+			case LSHUFFLE_OP:
+				err := g.LShuffle()
+				if err != nil {
+					return err
+				}
+			case TO_STR_OP:
+				err := g.ToStr()
 				if err != nil {
 					return err
 				}
-			case OR_OP:
-				err := g.Or()
+			case READFIELDS_OP:
+				err := g.ReadFields()
 				if err != nil {
 					return err
 				}
-			case NOT_OP:
-				err := g.Not()
+			case RETURN_OP:
+				return errReturn
+			case BOOL2STR_OP:
+				err := g.BoolToStr()
 				if err != nil {
 					return err
 				}
-			case EQUAL_OP:
-				err := g.Equal()
+			case STR2BOOL_OP:
+				err := g.StrToBool()
 				if err != nil {
 					return err
 				}
-			case NOT_EQUAL_OP:
-				g.NotEqual()
-			case EQUAL_TYP_OP:
-				err := g.EqualType()
+			case DOT_OP:
+				err := g.Dot()
 				if err != nil {
 					return err
 				}
-			case GT_THAN_OP:
-				err := g.GreaterThan()
+			case TABULATE_OP:
+				err := g.Tabulate()
 				if err != nil {
 					return err
 				}
-			case LS_THAN_OP:
-				err := g.LessThan()
+			case POP_PRINT_OP:
+				err := g.PopPrint()
 				if err != nil {
 					return err
 				}
-			case GT_THAN_EQ_OP:
-				err := g.GreaterThanEqual()
+			case ARGMIN_OP:
+				err := g.Argmin()
 				if err != nil {
 					return err
 				}
-			case LS_THAN_EQ_OP:
-				err := g.LessThanEqual()
+			case ARGMAX_OP:
+				err := g.Argmax()
 				if err != nil {
 					return err
 				}
-			case ROT_OP:
-				err := g.Rot()
+			case EMIT_OP:
+				err := g.Emit()
 				if err != nil {
 					return err
 				}
-			case VAR_ASSIGN_OP:
-				err := g.VarAssign()
+			case LTAKE_OP:
+				err := g.LTake()
+				if err != nil {
+					return err
+				}
+			case LDROP_OP:
+				err := g.LDrop()
+				if err != nil {
+					return err
+				}
+			case DURATION_OP:
+				err := g.Duration()
+				if err != nil {
+					return err
+				}
+			case DATEFMT_OP:
+				err := g.DateFmt()
+				if err != nil {
+					return err
+				}
+			case ABS_OP:
+				err := g.Abs()
+				if err != nil {
+					return err
+				}
+			case SLEEP_OP:
+				err := g.Pause()
+				if err != nil {
+					return err
+				}
+			case SQRT_OP:
+				err := g.Sqrt()
+				if err != nil {
+					return err
+				}
+			case DIGITSUM_OP:
+				err := g.DigitSum()
+				if err != nil {
+					return err
+				}
+			case BIT_AND_OP:
+				err := g.BitAnd()
+				if err != nil {
+					return err
+				}
+			case BIT_OR_OP:
+				err := g.BitOr()
+				if err != nil {
+					return err
+				}
+			case BIT_XOR_OP:
+				err := g.BitXor()
+				if err != nil {
+					return err
+				}
+			case SHIFT_LEFT_OP:
+				err := g.ShiftLeft()
+				if err != nil {
+					return err
+				}
+			case SHIFT_RIGHT_OP:
+				err := g.ShiftRight()
+				if err != nil {
+					return err
+				}
+			case REVDIGITS_OP:
+				err := g.RevDigits()
+				if err != nil {
+					return err
+				}
+			case APPLY_OP:
+				err := g.Apply()
+				if err != nil {
+					return err
+				}
+			case IFTE_OP:
+				err := g.Ifte()
+				if err != nil {
+					return err
+				}
+			case LCP_OP:
+				err := g.Lcp()
+				if err != nil {
+					return err
+				}
+			case INTERLEAVE_OP:
+				err := g.Interleave()
+				if err != nil {
+					return err
+				}
+			case HAMMING_OP:
+				err := g.Hamming()
+				if err != nil {
+					return err
+				}
+			case TRANSPOSE_OP:
+				err := g.Transpose()
+				if err != nil {
+					return err
+				}
+			case LCLAMP_OP:
+				err := g.LClamp()
+				if err != nil {
+					return err
+				}
+			case SAVE_STACK_OP:
+				err := g.SaveStack()
+				if err != nil {
+					return err
+				}
+			case LOAD_STACK_OP:
+				err := g.LoadStack()
+				if err != nil {
+					return err
+				}
+			case LSCALE_OP:
+				err := g.LScale()
+				if err != nil {
+					return err
+				}
+			case CUMSUM_OP:
+				err := g.CumSum()
+				if err != nil {
+					return err
+				}
+			case LEN_OP:
+				err := g.Len()
+				if err != nil {
+					return err
+				}
+			case INDEX_OP:
+				err := g.Index()
+				if err != nil {
+					return err
+				}
+			case APPEND_OP:
+				err := g.Append()
+				if err != nil {
+					return err
+				}
+			case STDDEV_OP:
+				err := g.StdDev()
+				if err != nil {
+					return err
+				}
+			case SUBSTR_OP:
+				err := g.Substr()
+				if err != nil {
+					return err
+				}
+			case HISTOGRAM_OP:
+				err := g.Histogram()
+				if err != nil {
+					return err
+				}
+			case UPPER_OP:
+				err := g.Upper()
+				if err != nil {
+					return err
+				}
+			case LOWER_OP:
+				err := g.Lower()
+				if err != nil {
+					return err
+				}
+			case ENUMERATE_OP:
+				err := g.Enumerate()
+				if err != nil {
+					return err
+				}
+			case SPLIT_OP:
+				err := g.Split()
+				if err != nil {
+					return err
+				}
+			case CHUNK_OP:
+				err := g.Chunk()
+				if err != nil {
+					return err
+				}
+			case JOIN_OP:
+				err := g.Join()
+				if err != nil {
+					return err
+				}
+			case LDIFF_OP:
+				err := g.LDiff()
+				if err != nil {
+					return err
+				}
+			case LUNION_OP:
+				err := g.LUnion()
+				if err != nil {
+					return err
+				}
+			case LINTERSECT_OP:
+				err := g.LIntersect()
+				if err != nil {
+					return err
+				}
+			case CONTAINS_OP:
+				err := g.Contains()
+				if err != nil {
+					return err
+				}
+			case TOMAP_OP:
+				err := g.ToMap()
+				if err != nil {
+					return err
+				}
+			case MKEYS_OP:
+				err := g.MKeys()
+				if err != nil {
+					return err
+				}
+			case MVALUES_OP:
+				err := g.MValues()
+				if err != nil {
+					return err
+				}
+			case MODE_OP:
+				err := g.Mode()
+				if err != nil {
+					return err
+				}
+			}
+		} else if op.Type == KeyWord {
+			if procedure, ok := op.Value.(Procedure); ok {
+				// defining a procedure only registers it, the body runs when it is called
+				if _, err := matchBlocks(procedure.Body); err != nil {
+					return err
+				}
+				g.Procedures[procedure.Name] = procedure.Body
+				continue
+			}
+
+			if declaration, ok := op.Value.(Declaration); ok {
+				// like a declaration outside of a scope, this leaves the variable on the stack
+				err := g.declare(declaration.Variable)
+				if err != nil {
+					return err
+				}
+
+				err = g.Push(StackElement{Type: Identifier, Value: declaration.Variable.Name})
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			switch op.Value {
+			case "if":
+				cond, err := g.popCondition("if")
+				if err != nil {
+					return err
+				}
+
+				// skip to the else or end, the loop then steps past it
+				if !cond {
+					i = jumps[i]
+				}
+			case "else":
+				// the if branch ran, so skip the else branch
+				i = jumps[i]
+			case "do":
+				cond, err := g.popCondition("do")
+				if err != nil {
+					return err
+				}
+
+				if !cond {
+					// the loop is done, skip past its end
+					delete(iterations, i)
+					i = jumps[i]
+					break
+				}
+
+				iterations[i]++
+				if iterations[i] > g.MaxLoopIterations {
+					return fmt.Errorf("ERROR: loop exceeded the maximum of %d iterations", g.MaxLoopIterations)
+				}
+			case "while":
+				// marks where the condition of a loop starts, the end of the loop jumps back here
+			case "scope":
+				g.scopes = append(g.scopes, make(map[string]Variable))
+			case "end":
+				start, ok := jumps[i]
+				if ok && program[start].Value == "scope" {
+					// the variables declared in the scope go away with it
+					g.scopes = g.scopes[:len(g.scopes)-1]
+					break
+				}
+
+				// the end of a loop jumps back to its while so the condition runs again
+				if ok {
+					i = start
+				}
+			default:
+				err := g.Call(op.Value.(string))
 				if err != nil {
 					return err
 				}
 			}
 		} else {
-			err := g.Push(op)
+			// list literals are copied so running the same code twice does not share them
+			err := g.Push(copyElement(op))
 			if err != nil {
 				return err
 			}
 		}
 	}
 
-	if g.StrictMode {
-		if len(g.ExecStack) > 0 {
-			return fmt.Errorf("ERROR: unconsumed elements remain on the stack\n\t%v", g.ExecStack)
-		}
+	return nil
+}
+
+// Run tokenizes and executes source on a new interpreter and returns the final stack.
+// It does not read files, exit or panic, so Gorth can be embedded in other programs
+func Run(source string, debug, strict bool) ([]StackElement, error) {
+	g := NewGorth(debug, strict)
+	err := g.Run(source)
+	return g.ExecStack, err
+}
+
+// Run tokenizes source and executes it on g, using the variables it declares
+func (g *Gorth) Run(source string) error {
+	program, variables, err := Tokenize(source)
+	if err != nil {
+		return err
+	}
+
+	// disabled operators are rejected before anything runs, execute still checks them
+	// for programs run with ExecuteProgram directly
+	err = g.checkDisabled(program)
+	if err != nil {
+		return err
+	}
+
+	// the tokenized variables are merged into the existing ones instead of replacing them
+	if g.VariableMap == nil {
+		g.VariableMap = make(map[string]Variable)
+	}
+	for name, variable := range variables {
+		g.VariableMap[name] = variable
 	}
 
 	if g.DebugMode {
-		fmt.Printf("Program stack at end of execution\n\t%v\n", g.ExecStack)
+		fmt.Fprintln(g.Out, "Variables: ", g.VariableMap)
+		fmt.Fprintln(g.Out, "Program: ", program)
+		fmt.Fprintf(g.Out, "Program stack at start of execution\n\t%v\n", g.ExecStack)
+	}
+
+	return g.ExecuteProgram(program)
+}
+
+// checkDisabled returns an error for the first disabled operator in program, including the ones
+// inside quotations and procedure bodies
+func (g *Gorth) checkDisabled(program []StackElement) error {
+	for _, token := range program {
+		switch token.Type {
+		case Operator:
+			if g.DisabledOps[token.Value.(Operation)] {
+				return disabledError(token.Value.(Operation))
+			}
+		case List, Quotation:
+			err := g.checkDisabled(token.Value.([]StackElement))
+			if err != nil {
+				return err
+			}
+		case KeyWord:
+			if procedure, ok := token.Value.(Procedure); ok {
+				err := g.checkDisabled(procedure.Body)
+				if err != nil {
+					return err
+				}
+			}
+		}
 	}
 
 	return nil
 }
 
+func disabledError(op Operation) error {
+	return fmt.Errorf("ERROR: operator %v is disabled", operatorName(op))
+}
+
 func PrintUsage() {
 	fmt.Println("Usage: gorth <filename> [options]")
 	fmt.Println("  filename: the name of the .gorth file to execute")
 	fmt.Println("  options:")
 	fmt.Println("    -d: optional enable debug mode")
 	fmt.Println("    -s: optional enable strict mode")
+	fmt.Println("    -t: optional allow assignments to change the type of a variable")
+	fmt.Println("    --metrics-json: optional print execution metrics as JSON after the run")
+}
+
+// cliOptions are the flags that can follow the file name on the command line
+type cliOptions struct {
+	debugMode     bool
+	strictMode    bool
+	dynamicTyping bool
+	metricsJSON   bool
+}
+
+// parseOptions reads the flags after the file name, they can be given in any order
+func parseOptions(flags []string) (cliOptions, error) {
+	var options cliOptions
+	for _, flag := range flags {
+		switch flag {
+		case "-d":
+			options.debugMode = true
+		case "-s":
+			options.strictMode = true
+		case "-t":
+			options.dynamicTyping = true
+		case "--metrics-json":
+			options.metricsJSON = true
+		default:
+			return cliOptions{}, fmt.Errorf("Invalid option: %s", flag)
+		}
+	}
+
+	return options, nil
 }
 
 func main() {
@@ -2332,11 +6468,6 @@ func main() {
 		return
 	}
 
-	// check if there are too many arguments
-	if len(args) > 3 {
-		panic("Too many arguments provided")
-	}
-
 	// check if the first argument is a .gorth file
 	if !strings.HasSuffix(args[0], ".gorth") {
 		panic(fmt.Sprintf("File %s is not a .gorth file", args[0]))
@@ -2355,45 +6486,20 @@ func main() {
 	}
 
 	// get the other arguments even if there are not in the correct order
-	debugMode := false
-	strictMode := false
-
-	for _, arg := range args[1:] {
-		switch arg {
-		case "-d":
-			debugMode = true
-		case "-s":
-			strictMode = true
-		default:
-			panic(fmt.Sprintf("Invalid option: %s", arg))
-		}
-	}
-
-	// parse the program
-	program, variables, err := Tokenize(strings.Join(lines, " "))
-
+	options, err := parseOptions(args[1:])
 	if err != nil {
-		panic(err)
+		panic(err.Error())
 	}
 
 	// create a new gorth instance
-	g := NewGorth(debugMode, strictMode)
-
-	g.VariableMap = variables
-
-	if g.DebugMode {
-		fmt.Println("Variables: ", g.VariableMap)
-		fmt.Println("Program: ", program)
-	}
+	g := NewGorth(options.debugMode, options.strictMode)
+	g.DynamicTyping = options.dynamicTyping
 
 	start := time.Now()
 
-	if g.DebugMode {
-		fmt.Printf("Program stack at start of execution\n\t%v\n", g.ExecStack)
-	}
-
-	// execute the program
-	err = g.ExecuteProgram(program)
+	// parse and execute the program
+	// lines are joined with newlines so tokenizer errors can point at a line of the file
+	err = g.Run(strings.Join(lines, "\n"))
 
 	end := time.Now()
 
@@ -2403,4 +6509,12 @@ func main() {
 	} else {
 		fmt.Printf("Program simulation completed in %v seconds\n", end.Sub(start).Seconds())
 	}
+
+	if options.metricsJSON {
+		metrics, err := json.Marshal(g.Metrics())
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(metrics))
+	}
 }
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"math"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 type TestCase []struct {
@@ -72,7 +76,8 @@ func TestReadGorthFile(t *testing.T) {
 	}
 
 	// Verify the result
-	expectedLines := []string{"line 1", "line 2", "line 3"}
+	// comments are stripped by Tokenize, not while reading
+	expectedLines := []string{"line 1", "line 2", "# comment", "line 3"}
 	if len(lines) != len(expectedLines) {
 		t.Errorf("Expected %d lines, but got %d lines", len(expectedLines), len(lines))
 	}
@@ -83,6 +88,142 @@ func TestReadGorthFile(t *testing.T) {
 		}
 	}
 }
+
+func TestReadGorthFileInlineComments(t *testing.T) {
+	filename := "testfile_comments.txt"
+
+	testData := "5 dup + # double it\n\"# not a comment\" print\n\"a # b\" # trailing\n"
+	if err := os.WriteFile(filename, []byte(testData), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	lines, err := ReadGorthFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read Gorth file: %v", err)
+	}
+
+	expectedLines := []string{"5 dup + # double it", `"# not a comment" print`, `"a # b" # trailing`}
+	if !reflect.DeepEqual(lines, expectedLines) {
+		t.Errorf("Expected lines %q, but got %q", expectedLines, lines)
+	}
+
+	stack, err := Run(strings.Join(lines, "\n"), false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: Int, Value: 10}, {Type: String, Value: "# not a comment"}, {Type: String, Value: "a # b"}}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, stack)
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	testCases := []struct {
+		title       string
+		source      string
+		expected    string
+		expectedErr error
+	}{
+		{
+			title:    "Test a line comment",
+			source:   "5 dup + # double it",
+			expected: "5 dup + ",
+		},
+		{
+			title:    "Test a line comment without a space",
+			source:   "5 dup +#double it",
+			expected: "5 dup +",
+		},
+		{
+			title:    "Test a whole line comment between lines",
+			source:   "1\n# whole line\n2",
+			expected: "1\n\n2",
+		},
+		{
+			title:    "Test a # inside a string literal",
+			source:   `"a # b" # comment`,
+			expected: `"a # b" `,
+		},
+		{
+			title:    "Test a line comment containing a quote and a block comment opener",
+			source:   "1 # don't (* open\n2",
+			expected: "1 \n2",
+		},
+		{
+			title:    "Test a # inside a block comment",
+			source:   "1 (* see # below *) 2",
+			expected: "1   2",
+		},
+		{
+			title:    "Test an inline block comment",
+			source:   "1 (* one *) 2 +",
+			expected: "1   2 +",
+		},
+		{
+			title:    "Test a multi-line block comment",
+			source:   "1\n(* first line\nsecond line *)\n2 +",
+			expected: "1\n \n2 +",
+		},
+		{
+			title:    "Test a block comment between tokens without spaces",
+			source:   "1(*one*)2",
+			expected: "1 2",
+		},
+		{
+			title:    "Test block comment delimiters inside a string literal",
+			source:   `"(* not a comment *)" print`,
+			expected: `"(* not a comment *)" print`,
+		},
+		{
+			title:    "Test a block comment containing a quote",
+			source:   `1 (* say "hi *) 2`,
+			expected: "1   2",
+		},
+		{
+			title:       "Test an unterminated block comment",
+			source:      "1 2 +\n  3 (* never closed",
+			expectedErr: errors.New("ERROR: block comment opened at line 2, column 5 is never closed"),
+		},
+		{
+			title:       "Test an unterminated block comment after a closed one",
+			source:      "(* a\nb *) é (* c",
+			expectedErr: errors.New("ERROR: block comment opened at line 2, column 8 is never closed"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			got, err := StripComments(tc.source)
+			if !reflect.DeepEqual(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, but got: %v", tc.expectedErr, err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %q, but got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestBlockCommentProgram(t *testing.T) {
+	stack, err := Run("(* adds\n   two numbers *)\n1 (* one *) 2 + \"(* kept *)\"", false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: Int, Value: 3}, {Type: String, Value: "(* kept *)"}}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, stack)
+	}
+
+	_, err = Run("1 2 + (* oops", false, false)
+	expectedErr := errors.New("ERROR: block comment opened at line 1, column 7 is never closed")
+	if !reflect.DeepEqual(err, expectedErr) {
+		t.Errorf("Expected error: %v, but got: %v", expectedErr, err)
+	}
+}
+
 func TestTokenize(t *testing.T) {
 	testCases := []struct {
 		input       string
@@ -100,6 +241,166 @@ func TestTokenize(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		// hex and binary literals tokenize as integers
+		{
+			input: "0xFF",
+			expected: []StackElement{
+				{Type: Int, Value: 255},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// hex and binary literals tokenize as integers
+		{
+			input: "0b1010",
+			expected: []StackElement{
+				{Type: Int, Value: 10},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// hex and binary literals tokenize as integers
+		{
+			input: "-0x10",
+			expected: []StackElement{
+				{Type: Int, Value: -16},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// hex and binary literals tokenize as integers
+		{
+			input: "-0b11",
+			expected: []StackElement{
+				{Type: Int, Value: -3},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// hex and binary literals tokenize as integers
+		{
+			input: "0xdeadBEEF",
+			expected: []StackElement{
+				{Type: Int, Value: 3735928559},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// hex and binary literals can be assigned to variables
+		{
+			input: "/mask 0xF0 def",
+			expected: []StackElement{
+				{Type: Identifier, Value: "mask"},
+			},
+			expectedMap: map[string]Variable{
+				"mask": {Name: "mask", Type: Int, Value: 240},
+			},
+			expectedErr: nil,
+		},
+		// block keywords tokenize as keywords
+		{
+			input: "true if 1 else 2 end",
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+				{Type: KeyWord, Value: "if"},
+				{Type: Int, Value: 1},
+				{Type: KeyWord, Value: "else"},
+				{Type: Int, Value: 2},
+				{Type: KeyWord, Value: "end"},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// multi character operators tokenize as a single operator
+		{
+			input: "5 ++",
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Operator, Value: INC_OP},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// multi character operators tokenize as a single operator
+		{
+			input: "5 --",
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Operator, Value: DEC_OP},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// multi character operators tokenize as a single operator
+		{
+			input: "5 >=",
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Operator, Value: GT_THAN_EQ_OP},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// multi character operators tokenize as a single operator
+		{
+			input: "5 <=",
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Operator, Value: LS_THAN_EQ_OP},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// multi character operators tokenize as a single operator
+		{
+			input: "5 ==",
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Operator, Value: EQUAL_OP},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// multi character operators tokenize as a single operator
+		{
+			input: "5 ===",
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Operator, Value: EQUAL_TYP_OP},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// multi character operators tokenize as a single operator
+		{
+			input: "5 !=",
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Operator, Value: NOT_EQUAL_OP},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// multi character operators tokenize as a single operator
+		{
+			input: "5 &&",
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Operator, Value: AND_OP},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
+		// multi character operators tokenize as a single operator
+		{
+			input: "5 ||",
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Operator, Value: OR_OP},
+			},
+			expectedMap: map[string]Variable{},
+			expectedErr: nil,
+		},
 		// Add more test cases here
 	}
 
@@ -156,8 +457,8 @@ func TestPop(t *testing.T) {
 	_, err := g.Pop()
 	if err == nil {
 		t.Error("Expected error: cannot pop from an empty stack, but got nil")
-	} else if err.Error() != "ERROR: cannot pop from an empty stack" {
-		t.Errorf("Expected error message: %q, but got: %q", "ERROR: cannot pop from an empty stack", err.Error())
+	} else if !errors.Is(err, ErrStackEmpty) {
+		t.Errorf("Expected error wrapping %q, but got: %v", ErrStackEmpty, err)
 	}
 
 	// Test popping from a non-empty stack
@@ -185,8 +486,8 @@ func TestDrop(t *testing.T) {
 	err := g.Drop()
 	if err == nil {
 		t.Error("Expected error: cannot drop from an empty stack, but got nil")
-	} else if err.Error() != "ERROR: cannot drop from an empty stack" {
-		t.Errorf("Expected error message: %q, but got: %q", "ERROR: cannot drop from an empty stack", err.Error())
+	} else if !errors.Is(err, ErrStackEmpty) {
+		t.Errorf("Expected error wrapping %q, but got: %v", ErrStackEmpty, err)
 	}
 
 	// Test dropping from a non-empty stack
@@ -209,31 +510,62 @@ func TestDump(t *testing.T) {
 	// Test dumping an integer value
 	g.ExecStack = append(g.ExecStack, StackElement{Type: Int, Value: 10})
 
-	oldStdout := os.Stdout // Keep a reference to the original stdout
-	r, w, _ := os.Pipe()   // Create a pipe to capture stdout
-	os.Stdout = w          // Replace stdout with the write end of the pipe
-
-	// Capture the output by reading from the read end of the pipe
-	capturedOutput := make(chan string)
-	go func() {
-		out, _ := ioutil.ReadAll(r)
-		capturedOutput <- string(out)
-	}()
+	var out bytes.Buffer
+	g.Out = &out
 
 	err := g.Dump()
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
-	w.Close()             // Close the write end of the pipe to unblock the reader
-	os.Stdout = oldStdout // Restore the original stdout
-
 	// Check the captured output
 	expectedOutput := "10\n"
-	actualOutput := <-capturedOutput
+	actualOutput := out.String()
+	if actualOutput != expectedOutput {
+		t.Errorf("Expected output: %q, but got: %q", expectedOutput, actualOutput)
+	}
+}
+
+func TestPopPrint(t *testing.T) {
+	g := NewGorth(false, false)
+
+	g.VariableMap["x"] = Variable{Type: Float, Value: 2.5, Name: "x"}
+	g.ExecStack = append(g.ExecStack,
+		StackElement{Type: String, Value: "below"},
+		StackElement{Type: Identifier, Value: "x"},
+		StackElement{Type: Int, Value: 10},
+	)
+
+	var out bytes.Buffer
+	g.Out = &out
+
+	for i := 0; i < 2; i++ {
+		before := len(g.ExecStack)
+		err := g.PopPrint()
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if len(g.ExecStack) != before-1 {
+			t.Errorf("Expected stack length %d, but got %d", before-1, len(g.ExecStack))
+		}
+	}
+
+	expectedOutput := "10\n2.5\n"
+	actualOutput := out.String()
 	if actualOutput != expectedOutput {
 		t.Errorf("Expected output: %q, but got: %q", expectedOutput, actualOutput)
 	}
+
+	// non printable and empty stacks are errors
+	g.ExecStack = []StackElement{{Type: List, Value: []StackElement{}}}
+	if err := g.PopPrint(); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch, but got: %v", err)
+	}
+
+	g.ExecStack = []StackElement{}
+	if err := g.PopPrint(); !errors.Is(err, ErrStackEmpty) {
+		t.Errorf("Expected ErrStackEmpty, but got: %v", err)
+	}
 }
 
 func TestDup(t *testing.T) {
@@ -314,11 +646,10 @@ func TestRot(t *testing.T) {
 
 	// Test with less than 3 elements on stack
 	err := g.Rot()
-	expectedErr := "ERROR: at least 3 elements need to be on stack to perform ROT_OP"
 	if err == nil {
-		t.Error("Expected error: ", expectedErr)
-	} else if err.Error() != expectedErr {
-		t.Errorf("Expected error: %q, but got: %q", expectedErr, err.Error())
+		t.Error("Expected error: ", ErrStackEmpty)
+	} else if !errors.Is(err, ErrStackEmpty) {
+		t.Errorf("Expected error wrapping %q, but got: %v", ErrStackEmpty, err)
 	}
 
 	// Test with 3 elements on stack
@@ -340,16 +671,60 @@ func TestRot(t *testing.T) {
 		t.Errorf("Expected stack: %v, but got: %v", expectedStack, g.ExecStack)
 	}
 }
+func TestRotBack(t *testing.T) {
+	g := NewGorth(false, false)
+
+	// Test with less than 3 elements on stack
+	err := g.RotBack()
+	if err == nil {
+		t.Error("Expected error: ", ErrStackEmpty)
+	} else if !errors.Is(err, ErrStackEmpty) {
+		t.Errorf("Expected error wrapping %q, but got: %v", ErrStackEmpty, err)
+	}
+
+	// Test with 3 elements on stack
+	g.ExecStack = append(g.ExecStack, StackElement{Type: Int, Value: 1})
+	g.ExecStack = append(g.ExecStack, StackElement{Type: Int, Value: 2})
+	g.ExecStack = append(g.ExecStack, StackElement{Type: Int, Value: 3})
+
+	err = g.RotBack()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expectedStack := []StackElement{
+		{Type: Int, Value: 3},
+		{Type: Int, Value: 1},
+		{Type: Int, Value: 2},
+	}
+	if !reflect.DeepEqual(g.ExecStack, expectedStack) {
+		t.Errorf("Expected stack: %v, but got: %v", expectedStack, g.ExecStack)
+	}
+
+	// Test that -rot undoes rot
+	err = g.Rot()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expectedStack = []StackElement{
+		{Type: Int, Value: 1},
+		{Type: Int, Value: 2},
+		{Type: Int, Value: 3},
+	}
+	if !reflect.DeepEqual(g.ExecStack, expectedStack) {
+		t.Errorf("Expected stack: %v, but got: %v", expectedStack, g.ExecStack)
+	}
+}
 func TestPeek(t *testing.T) {
 	g := NewGorth(false, false)
 
 	// Test peeking from an empty stack
 	_, err := g.Peek()
-	expectedErr := "ERROR: cannot PEEK_OP at an empty stack"
 	if err == nil {
-		t.Error("Expected error: ", expectedErr)
-	} else if err.Error() != expectedErr {
-		t.Errorf("Expected error: %q, but got: %q", expectedErr, err.Error())
+		t.Error("Expected error: ", ErrStackEmpty)
+	} else if !errors.Is(err, ErrStackEmpty) {
+		t.Errorf("Expected error wrapping %q, but got: %v", ErrStackEmpty, err)
 	}
 
 	// Test peeking from a non-empty stack
@@ -492,7 +867,7 @@ func TestAdd(t *testing.T) {
 			expected: []StackElement{
 				{Type: Float, Value: 7.5},
 			},
-			expectedErr: errors.New("ERROR: cannot perform ADD_OP on different types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test addition with variables of different types",
 		},
 	}
@@ -507,7 +882,7 @@ func TestAdd(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -678,7 +1053,7 @@ func TestSub(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -797,7 +1172,7 @@ func TestMul(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -904,44 +1279,226 @@ func TestDiv(t *testing.T) {
 			expectedErr: nil,
 			title:       "Test variable division with different types",
 		},
-		// Add more test cases as needed
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.title, func(t *testing.T) {
-			g := NewGorth(false, false)
-			g.ExecStack = tc.stack
-			g.VariableMap = tc.variableMap
-
-			err := g.Div()
-			if err != nil {
-				if tc.expectedErr == nil {
-					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
-					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
-				}
-			}
-
-			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
-				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
-			}
-		})
-	}
-}
-func TestMod(t *testing.T) {
-	var testCases = []struct {
-		stack       []StackElement
-		expected    []StackElement
-		expectedErr error
-		title       string
-	}{
-		// Test integer modulo
+		// Test integer division by zero
 		{
 			stack: []StackElement{
 				{Type: Int, Value: 10},
-				{Type: Int, Value: 5},
-			},
-			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+			title:       "Test integer division by zero",
+		},
+		// Test float division by zero
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.0},
+				{Type: Float, Value: 0.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+			title:       "Test float division by zero",
+		},
+		// Test mixed number division by zero (float and int)
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.5},
+				{Type: Int, Value: 0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+			title:       "Test mixed number division by zero (float and int)",
+		},
+		// Test mixed number division by zero (int and float)
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Float, Value: 0.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+			title:       "Test mixed number division by zero (int and float)",
+		},
+		// Test variable division by zero
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Identifier, Value: "y"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 10},
+				"y": {Name: "y", Type: Int, Value: 0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+			title:       "Test variable division by zero",
+		},
+		// Test float variable division by zero
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Identifier, Value: "y"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Float, Value: 1.5},
+				"y": {Name: "y", Type: Float, Value: 0.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+			title:       "Test float variable division by zero",
+		},
+		// Test literal divided by a zero variable
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 10},
+				{Type: Identifier, Value: "y"},
+			},
+			variableMap: map[string]Variable{
+				"y": {Name: "y", Type: Int, Value: 0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+			title:       "Test literal divided by a zero variable",
+		},
+		// Test literal divided by a zero float variable
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 10},
+				{Type: Identifier, Value: "y"},
+			},
+			variableMap: map[string]Variable{
+				"y": {Name: "y", Type: Float, Value: 0.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+			title:       "Test literal divided by a zero float variable",
+		},
+		// Test variable divided by a zero literal
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 0},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 10},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+			title:       "Test variable divided by a zero literal",
+		},
+		// Test float variable divided by a zero float literal
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Float, Value: 0.0},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Float, Value: 2.5},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+			title:       "Test float variable divided by a zero float literal",
+		},
+		// Test literal divided by a variable
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 10},
+				{Type: Identifier, Value: "y"},
+			},
+			variableMap: map[string]Variable{
+				"y": {Name: "y", Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test literal divided by a variable",
+		},
+		// Test variable divided by a literal
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 2},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 10},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test variable divided by a literal",
+		},
+		// Test float variable divided by an integer literal
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 2},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Float, Value: 2.5},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 1.25},
+			},
+			expectedErr: nil,
+			title:       "Test float variable divided by an integer literal",
+		},
+		// Test integer literal divided by a float variable
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Identifier, Value: "y"},
+			},
+			variableMap: map[string]Variable{
+				"y": {Name: "y", Type: Float, Value: 2.0},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 2.5},
+			},
+			expectedErr: nil,
+			title:       "Test integer literal divided by a float variable",
+		},
+		// Add more test cases as needed
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Div()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+func TestMod(t *testing.T) {
+	var testCases = []struct {
+		stack       []StackElement
+		expected    []StackElement
+		expectedErr error
+		title       string
+	}{
+		// Test integer modulo
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 10},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
 				{Type: Int, Value: 0},
 			},
 			expectedErr: nil,
@@ -954,7 +1511,7 @@ func TestMod(t *testing.T) {
 				{Type: Float, Value: 2.0},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform MOD_OP on different types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test float modulo",
 		},
 		// Test mixed number modulo (int and float)
@@ -964,7 +1521,7 @@ func TestMod(t *testing.T) {
 				{Type: Float, Value: 2.5},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform MOD_OP on different types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test mixed number modulo (int and float)",
 		},
 		// Test mixed number modulo (float and int)
@@ -974,7 +1531,7 @@ func TestMod(t *testing.T) {
 				{Type: Int, Value: 5},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform MOD_OP on different types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test mixed number modulo (float and int)",
 		},
 		// Add more test cases as needed
@@ -995,7 +1552,7 @@ func TestMod(t *testing.T) {
 				{Type: Int, Value: 0},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot divide by zero"),
+			expectedErr: ErrDivideByZero,
 			title:       "Test integer modulo with divisor 0",
 		},
 		{
@@ -1022,7 +1579,7 @@ func TestMod(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -1037,6 +1594,7 @@ func TestMod(t *testing.T) {
 func TestExp(t *testing.T) {
 	var testCases = []struct {
 		stack       []StackElement
+		variableMap map[string]Variable
 		expected    []StackElement
 		expectedErr error
 		title       string
@@ -1120,11 +1678,55 @@ func TestExp(t *testing.T) {
 				{Type: Int, Value: -3},
 			},
 			expected: []StackElement{
-				{Type: Int, Value: 0},
+				{Type: Float, Value: 0.125},
 			},
 			expectedErr: nil,
 			title:       "Test integer exponentiation with negative exponent",
 		},
+		// negative exponents with variables also give a float
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Identifier, Value: "y"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 4},
+				"y": {Name: "y", Type: Int, Value: -1},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 0.25},
+			},
+			expectedErr: nil,
+			title:       "Test integer variable exponentiation with negative exponent",
+		},
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 2},
+				{Type: Identifier, Value: "y"},
+			},
+			variableMap: map[string]Variable{
+				"y": {Name: "y", Type: Int, Value: -2},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 0.25},
+			},
+			expectedErr: nil,
+			title:       "Test integer exponentiation with a negative exponent variable",
+		},
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: -1},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 0.5},
+			},
+			expectedErr: nil,
+			title:       "Test integer variable base with negative exponent",
+		},
 		{
 			stack: []StackElement{
 				{Type: Int, Value: 2},
@@ -1146,12 +1748,15 @@ func TestExp(t *testing.T) {
 		t.Run(tc.title, func(t *testing.T) {
 			g := NewGorth(false, false)
 			g.ExecStack = tc.stack
+			if tc.variableMap != nil {
+				g.VariableMap = tc.variableMap
+			}
 
 			err := g.Exp()
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -1181,7 +1786,7 @@ func TestInc(t *testing.T) {
 				{Type: String, Value: "Hello"},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform INC_OP on different types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test incrementing a string",
 		},
 		{
@@ -1237,7 +1842,7 @@ func TestInc(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -1267,7 +1872,7 @@ func TestDec(t *testing.T) {
 				{Type: String, Value: "Hello"},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform DEC_OP on different types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test decrementing a string",
 		},
 		{
@@ -1323,7 +1928,7 @@ func TestDec(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -1353,7 +1958,7 @@ func TestNeg(t *testing.T) {
 				{Type: String, Value: "Hello"},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform NEG_OP on different types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test negating a string",
 		},
 		{
@@ -1409,7 +2014,7 @@ func TestNeg(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -1421,6 +2026,34 @@ func TestNeg(t *testing.T) {
 	}
 }
 
+func TestNegProgram(t *testing.T) {
+	program, variables, err := Tokenize("5 neg")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedProgram := []StackElement{
+		{Type: Int, Value: 5},
+		{Type: Operator, Value: NEG_OP},
+	}
+	if !reflect.DeepEqual(program, expectedProgram) {
+		t.Errorf("Expected program: %v, but got: %v", expectedProgram, program)
+	}
+
+	g := NewGorth(false, false)
+	g.VariableMap = variables
+
+	err = g.ExecuteProgram(program)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: Int, Value: -5}}
+	if !reflect.DeepEqual(g.ExecStack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+	}
+}
+
 func TestSwap(t *testing.T) {
 	var testCases = TestCase{
 		{
@@ -1526,13 +2159,13 @@ func TestSwap(t *testing.T) {
 				{Type: Int, Value: 5},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot pop from an empty stack"),
+			expectedErr: ErrStackEmpty,
 			title:       "Test swapping with only one element on stack",
 		},
 		{
 			stack:       []StackElement{},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot pop from an empty stack"),
+			expectedErr: ErrStackEmpty,
 			title:       "Test swapping with no elements on stack",
 		},
 		{
@@ -1561,7 +2194,7 @@ func TestSwap(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -1582,7 +2215,7 @@ func TestAnd(t *testing.T) {
 				{Type: Int, Value: 10},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform AND_OP on non boolean types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test integer AND",
 		},
 		// Test float AND
@@ -1592,7 +2225,7 @@ func TestAnd(t *testing.T) {
 				{Type: Float, Value: 2.0},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform AND_OP on non boolean types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test float AND",
 		},
 		// Test mixed number AND (int and float)
@@ -1602,7 +2235,7 @@ func TestAnd(t *testing.T) {
 				{Type: Float, Value: 2.5},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform AND_OP on non boolean types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test mixed number AND (int and float)",
 		},
 		// Test mixed number AND (float and int)
@@ -1612,7 +2245,7 @@ func TestAnd(t *testing.T) {
 				{Type: Int, Value: 5},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform AND_OP on non boolean types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test mixed number AND (float and int)",
 		},
 		// Test variable AND
@@ -1626,7 +2259,7 @@ func TestAnd(t *testing.T) {
 				"y": {Name: "y", Type: Int, Value: 10},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform AND_OP on non boolean types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test variable AND",
 		},
 		// Test variable AND with undeclared variable
@@ -1653,7 +2286,7 @@ func TestAnd(t *testing.T) {
 				"y": {Name: "y", Type: Float, Value: 2.5},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform AND_OP on non boolean types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test variable AND with different types",
 		},
 		// Test boolean AND (true and true)
@@ -1680,50 +2313,119 @@ func TestAnd(t *testing.T) {
 			expectedErr: nil,
 			title:       "Test boolean AND (true and false)",
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.title, func(t *testing.T) {
-			g := NewGorth(false, false)
-			g.ExecStack = tc.stack
-			g.VariableMap = tc.variableMap
-
-			err := g.And()
-			if err != nil {
-				if tc.expectedErr == nil {
-					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
-					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
-				}
-			}
-
-			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
-				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
-			}
-		})
-	}
-}
-
-func TestOr(t *testing.T) {
-	var testCases = TestCase{
-		// Test integer OR
+		// Test AND with a bool variable and a literal bool
 		{
 			stack: []StackElement{
-				{Type: Int, Value: 5},
-				{Type: Int, Value: 10},
+				{Type: Bool, Value: true},
+				{Type: Identifier, Value: "x"},
 			},
-			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform OR_OP on non boolean types"),
-			title:       "Test integer OR",
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Bool, Value: true},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test AND with a bool variable and a literal bool",
 		},
-		// Test float OR
+		// Test AND with a literal bool and a bool variable
 		{
 			stack: []StackElement{
-				{Type: Float, Value: 3.14},
-				{Type: Float, Value: 2.0},
+				{Type: Identifier, Value: "x"},
+				{Type: Bool, Value: false},
 			},
-			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform OR_OP on non boolean types"),
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Bool, Value: true},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test AND with a literal bool and a bool variable",
+		},
+		// Test AND with a non boolean variable and a literal bool
+		{
+			stack: []StackElement{
+				{Type: Bool, Value: true},
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test AND with a non boolean variable and a literal bool",
+		},
+		// Test AND with a bool variable and a literal int
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Bool, Value: true},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test AND with a bool variable and a literal int",
+		},
+		// Test AND with a literal int and a bool variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 1},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Bool, Value: true},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test AND with a literal int and a bool variable",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.And()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestOr(t *testing.T) {
+	var testCases = TestCase{
+		// Test integer OR
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Int, Value: 10},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test integer OR",
+		},
+		// Test float OR
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 3.14},
+				{Type: Float, Value: 2.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
 			title:       "Test float OR",
 		},
 		// Test mixed number OR (int and float)
@@ -1733,7 +2435,7 @@ func TestOr(t *testing.T) {
 				{Type: Float, Value: 2.5},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform OR_OP on non boolean types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test mixed number OR (int and float)",
 		},
 		// Test mixed number OR (float and int)
@@ -1743,7 +2445,7 @@ func TestOr(t *testing.T) {
 				{Type: Int, Value: 5},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform OR_OP on non boolean types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test mixed number OR (float and int)",
 		},
 		// Test variable OR
@@ -1757,7 +2459,7 @@ func TestOr(t *testing.T) {
 				"y": {Name: "y", Type: Int, Value: 10},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform OR_OP on non boolean types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test variable OR",
 		},
 		// Test variable OR with undeclared variable
@@ -1784,7 +2486,7 @@ func TestOr(t *testing.T) {
 				"y": {Name: "y", Type: Float, Value: 2.5},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform OR_OP on non boolean types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test variable OR with different types",
 		},
 		// Test boolean OR (true and true)
@@ -1835,6 +2537,47 @@ func TestOr(t *testing.T) {
 			expectedErr: nil,
 			title:       "Test boolean OR (false and false)",
 		},
+		// Test OR with a bool variable and a literal bool
+		{
+			stack: []StackElement{
+				{Type: Bool, Value: false},
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Bool, Value: true},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test OR with a bool variable and a literal bool",
+		},
+		// Test OR with a non boolean variable and a literal bool
+		{
+			stack: []StackElement{
+				{Type: Bool, Value: true},
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: String, Value: "true"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test OR with a non boolean variable and a literal bool",
+		},
+		// Test OR with a literal int and a bool variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 1},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Bool, Value: false},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test OR with a literal int and a bool variable",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1847,7 +2590,7 @@ func TestOr(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -1975,7 +2718,7 @@ func TestNot(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -2053,6 +2796,58 @@ func TestEqual(t *testing.T) {
 			expectedErr: nil,
 			title:       "Test variable equality",
 		},
+		// Test float equality within epsilon
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 0.30000000000000004},
+				{Type: Float, Value: 0.3},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test float equality within epsilon",
+		},
+		// Test float inequality outside epsilon
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 0.3},
+				{Type: Float, Value: 0.31},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test float inequality outside epsilon",
+		},
+		// Test float and int equality within epsilon
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 2.0000000000000004},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test float and int equality within epsilon",
+		},
+		// Test float variable equality within epsilon
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Identifier, Value: "y"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Float, Value: 0.30000000000000004},
+				"y": {Name: "y", Type: Float, Value: 0.3},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test float variable equality within epsilon",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -2065,7 +2860,7 @@ func TestEqual(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -2196,7 +2991,7 @@ func TestEqualType(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -2299,7 +3094,7 @@ func TestGreaterThan(t *testing.T) {
 				"y": {Name: "y", Type: String, Value: "Hello"},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform GT_THAN_OP on different types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test variable greater than with different types",
 		},
 	}
@@ -2314,7 +3109,7 @@ func TestGreaterThan(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -2417,7 +3212,7 @@ func TestLessThan(t *testing.T) {
 				"y": {Name: "y", Type: String, Value: "Hello"},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform LS_THAN_OP on different types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test variable less than with different types",
 		},
 	}
@@ -2432,7 +3227,7 @@ func TestLessThan(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -2534,7 +3329,7 @@ func TestGreaterThanEqual(t *testing.T) {
 				"y": {Name: "y", Type: String, Value: "Hello"},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform GT_THAN_EQ_OP on different types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test variable greater than or equal with different types",
 		},
 	}
@@ -2549,7 +3344,7 @@ func TestGreaterThanEqual(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
 			}
@@ -2651,7 +3446,7 @@ func TestLessThanEqual(t *testing.T) {
 				"y": {Name: "y", Type: String, Value: "Hello"},
 			},
 			expected:    []StackElement{},
-			expectedErr: errors.New("ERROR: cannot perform LS_THAN_EQ_OP on different types"),
+			expectedErr: ErrTypeMismatch,
 			title:       "Test variable less than or equal with different types",
 		},
 	}
@@ -2666,9 +3461,111 @@ func TestLessThanEqual(t *testing.T) {
 			if err != nil {
 				if tc.expectedErr == nil {
 					t.Errorf("Unexpected error: %v", err)
-				} else if err.Error() != tc.expectedErr.Error() {
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestFib(t *testing.T) {
+	var testCases = TestCase{
+		// Test fib(0)
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test fib(0)",
+		},
+		// Test fib(10)
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 10},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 55},
+			},
+			expectedErr: nil,
+			title:       "Test fib(10)",
+		},
+		// Test the largest fibonacci number that fits in an int
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 92},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 7540113804746346429},
+			},
+			expectedErr: nil,
+			title:       "Test fib(92)",
+		},
+		// Test fib overflow
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 93},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: integer overflow in FIB_OP"),
+			title:       "Test fib overflow",
+		},
+		// Test fib with a negative number
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -1},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot perform FIB_OP on a negative number"),
+			title:       "Test fib with a negative number",
+		},
+		// Test fib with a variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 7},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 13},
+			},
+			expectedErr: nil,
+			title:       "Test fib with a variable",
+		},
+		// Test fib with a non integer
+		{
+			stack: []StackElement{
+				{Type: String, Value: "Hello"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test fib with a non integer",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Fib()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
 					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
 				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
 			}
 
 			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
@@ -2677,3 +3574,10439 @@ func TestLessThanEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestPrime(t *testing.T) {
+	var testCases = TestCase{
+		// Test prime with 2
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test prime with 2",
+		},
+		// Test prime with a larger prime
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 7919},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test prime with a larger prime",
+		},
+		// Test prime with a composite
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 91},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test prime with a composite",
+		},
+		// Test prime with a square of a prime
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 49},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test prime with a square of a prime",
+		},
+		// Test prime with 1
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test prime with 1",
+		},
+		// Test prime with 0
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test prime with 0",
+		},
+		// Test prime with a negative number
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -7},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test prime with a negative number",
+		},
+		// Test prime with a variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 13},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test prime with a variable",
+		},
+		// Test prime with a non integer
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 7.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test prime with a non integer",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Prime()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestRevBits(t *testing.T) {
+	var testCases = TestCase{
+		// Test reversing 4 bits
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 4},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 8},
+			},
+			expectedErr: nil,
+			title:       "Test reversing 4 bits",
+		},
+		// Test reversing a palindrome
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 9},
+				{Type: Int, Value: 4},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 9},
+			},
+			expectedErr: nil,
+			title:       "Test reversing a palindrome",
+		},
+		// Test reversing masks bits above the width (0b10110 -> 0b0110 -> 0b0110)
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 22},
+				{Type: Int, Value: 4},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expectedErr: nil,
+			title:       "Test reversing masks bits above the width",
+		},
+		// Test reversing a single bit
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test reversing a single bit",
+		},
+		// Test reversing 64 bits
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 64},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: -9223372036854775808},
+			},
+			expectedErr: nil,
+			title:       "Test reversing 64 bits",
+		},
+		// Test reversing with a zero width
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 0},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: REVBITS_OP width must be between 1 and 64"),
+			title:       "Test reversing with a zero width",
+		},
+		// Test reversing with a width above 64
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 65},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: REVBITS_OP width must be between 1 and 64"),
+			title:       "Test reversing with a width above 64",
+		},
+		// Test reversing a non integer
+		{
+			stack: []StackElement{
+				{Type: String, Value: "1"},
+				{Type: Int, Value: 4},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test reversing a non integer",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.RevBits()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestPopCount(t *testing.T) {
+	var testCases = TestCase{
+		// Test popcount of 0
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test popcount of 0",
+		},
+		// Test popcount of 7
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 7},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+			},
+			expectedErr: nil,
+			title:       "Test popcount of 7",
+		},
+		// Test popcount with high bits set (0x8000000000000001)
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -9223372036854775807},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test popcount with high bits set",
+		},
+		// Test popcount of a negative number
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 64},
+			},
+			expectedErr: nil,
+			title:       "Test popcount of a negative number",
+		},
+		// Test popcount of a non integer
+		{
+			stack: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test popcount of a non integer",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.PopCount()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestByteSwap(t *testing.T) {
+	var testCases = TestCase{
+		// Test 2 byte swap (0x1234 -> 0x3412)
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 4660},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 13330},
+			},
+			expectedErr: nil,
+			title:       "Test 2 byte swap",
+		},
+		// Test 4 byte swap (0x12345678 -> 0x78563412)
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 305419896},
+				{Type: Int, Value: 4},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2018915346},
+			},
+			expectedErr: nil,
+			title:       "Test 4 byte swap",
+		},
+		// Test 8 byte swap (0x0102030405060708 -> 0x0807060504030201)
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 72623859790382856},
+				{Type: Int, Value: 8},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 578437695752307201},
+			},
+			expectedErr: nil,
+			title:       "Test 8 byte swap",
+		},
+		// Test swap truncates to the width (0xAA1234 -> 0x3412)
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 11145780},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 13330},
+			},
+			expectedErr: nil,
+			title:       "Test swap truncates to the width",
+		},
+		// Test swap with an invalid width
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 4660},
+				{Type: Int, Value: 3},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: BSWAP_OP width must be 2, 4 or 8 bytes"),
+			title:       "Test swap with an invalid width",
+		},
+		// Test swap with a non integer
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.5},
+				{Type: Int, Value: 2},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test swap with a non integer",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.ByteSwap()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	program, variables, err := Tokenize("1 2 + dup * 3 swap drop")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g := NewGorth(false, false)
+	g.VariableMap = variables
+
+	err = g.ExecuteProgram(program)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	metrics := g.Metrics()
+
+	if metrics.Instructions != 8 {
+		t.Errorf("Expected 8 instructions, but got %d", metrics.Instructions)
+	}
+
+	expectedCounts := map[string]int{"+": 1, "dup": 1, "*": 1, "swap": 1, "drop": 1}
+	if !reflect.DeepEqual(metrics.OpCounts, expectedCounts) {
+		t.Errorf("Expected op counts: %v, but got: %v", expectedCounts, metrics.OpCounts)
+	}
+
+	if metrics.MaxStackDepth != 2 {
+		t.Errorf("Expected max stack depth to be 2, but got %d", metrics.MaxStackDepth)
+	}
+
+	if metrics.Duration < 0 {
+		t.Errorf("Expected a non-negative duration, but got %v", metrics.Duration)
+	}
+
+	// metrics are reset on every run
+	err = g.ExecuteProgram([]StackElement{{Type: Int, Value: 1}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	metrics = g.Metrics()
+	if metrics.Instructions != 1 || len(metrics.OpCounts) != 0 {
+		t.Errorf("Expected metrics to be reset, but got: %+v", metrics)
+	}
+}
+
+func TestPair(t *testing.T) {
+	var testCases = TestCase{
+		// Test pairing two integers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test pairing two integers",
+		},
+		// Test pairing keeps the rest of the stack
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: Int, Value: 1},
+				{Type: Float, Value: 2.5},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Float, Value: 2.5}}},
+			},
+			expectedErr: nil,
+			title:       "Test pairing keeps the rest of the stack",
+		},
+		// Test pairing a list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}}}, {Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test pairing a list",
+		},
+		// Test pairing a variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 2},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: String, Value: "hi"},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "hi"}, {Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test pairing a variable",
+		},
+		// Test pairing with one element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test pairing with one element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Pair()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestPairCopiesLists(t *testing.T) {
+	inner := []StackElement{{Type: Int, Value: 1}}
+
+	g := NewGorth(false, false)
+	g.ExecStack = []StackElement{
+		{Type: List, Value: inner},
+		{Type: Int, Value: 2},
+	}
+
+	err := g.Pair()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	inner[0] = StackElement{Type: Int, Value: 100}
+
+	expected := []StackElement{
+		{Type: List, Value: []StackElement{
+			{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			{Type: Int, Value: 2},
+		}},
+	}
+	if !reflect.DeepEqual(g.ExecStack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+	}
+}
+
+func TestUnpair(t *testing.T) {
+	var testCases = TestCase{
+		// Test unpairing a pair
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test unpairing a pair",
+		},
+		// Test unpairing a pair of mixed types
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: List, Value: []StackElement{{Type: Bool, Value: true}}}}},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: List, Value: []StackElement{{Type: Bool, Value: true}}},
+			},
+			expectedErr: nil,
+			title:       "Test unpairing a pair of mixed types",
+		},
+		// Test unpairing a list that is too short
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: UNPAIR_OP expects a list of 2 elements, but got 1"),
+			title:       "Test unpairing a list that is too short",
+		},
+		// Test unpairing a list that is too long
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: UNPAIR_OP expects a list of 2 elements, but got 3"),
+			title:       "Test unpairing a list that is too long",
+		},
+		// Test unpairing a non list
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test unpairing a non list",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Unpair()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestPick(t *testing.T) {
+	var testCases = TestCase{
+		// Test 0 pick behaves like dup
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test 0 pick behaves like dup",
+		},
+		// Test 1 pick copies the second element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test 1 pick copies the second element",
+		},
+		// Test picking the bottom of the stack
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 3},
+				{Type: String, Value: "a"},
+			},
+			expectedErr: nil,
+			title:       "Test picking the bottom of the stack",
+		},
+		// Test picking with a variable index
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Identifier, Value: "n"},
+			},
+			variableMap: map[string]Variable{
+				"n": {Name: "n", Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test picking with a variable index",
+		},
+		// Test picking out of range
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expectedErr: errors.New("ERROR: PICK_OP index 2 is out of range for a stack of 2 elements"),
+			title:       "Test picking out of range",
+		},
+		// Test picking with a negative index
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: -1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: errors.New("ERROR: PICK_OP index -1 is out of range for a stack of 1 elements"),
+			title:       "Test picking with a negative index",
+		},
+		// Test picking with a non integer index
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "0"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test picking with a non integer index",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Pick()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestGoValueType(t *testing.T) {
+	var testCases = TestCase{
+		// Test go type of an integer
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "int"},
+			},
+			expectedErr: nil,
+			title:       "Test go type of an integer",
+		},
+		// Test go type of a float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.5},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 1.5},
+				{Type: String, Value: "float64"},
+			},
+			expectedErr: nil,
+			title:       "Test go type of a float",
+		},
+		// Test go type of a list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: String, Value: "[]main.StackElement"},
+			},
+			expectedErr: nil,
+			title:       "Test go type of a list",
+		},
+		// Test go type of an identifier
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+			},
+			expected: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: String, Value: "string"},
+			},
+			expectedErr: nil,
+			title:       "Test go type of an identifier",
+		},
+		// Test go type of a nil value
+		{
+			stack: []StackElement{
+				{Type: Int, Value: nil},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: nil},
+				{Type: String, Value: "nil"},
+			},
+			expectedErr: nil,
+			title:       "Test go type of a nil value",
+		},
+		// Test go type on an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test go type on an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.GoValueType()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestRoll(t *testing.T) {
+	var testCases = TestCase{
+		// Test 0 roll is a no-op
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 3},
+			},
+			expectedErr: nil,
+			title:       "Test 0 roll is a no-op",
+		},
+		// Test 1 roll behaves like swap
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test 1 roll behaves like swap",
+		},
+		// Test 2 roll behaves like rot
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test 2 roll behaves like rot",
+		},
+		// Test rolling deeper than three elements
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 4},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 4},
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test rolling deeper than three elements",
+		},
+		// Test rolling out of range
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expectedErr: errors.New("ERROR: ROLL_OP index 2 is out of range for a stack of 2 elements"),
+			title:       "Test rolling out of range",
+		},
+		// Test rolling with a non integer index
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Float, Value: 0.0},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test rolling with a non integer index",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Roll()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestRollMatchesRot(t *testing.T) {
+	stack := []StackElement{
+		{Type: String, Value: "a"},
+		{Type: Int, Value: 1},
+		{Type: Float, Value: 2.5},
+		{Type: Bool, Value: true},
+	}
+
+	rot := NewGorth(false, false)
+	rot.ExecStack = append([]StackElement{}, stack...)
+	err := rot.Rot()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	roll := NewGorth(false, false)
+	roll.ExecStack = append(append([]StackElement{}, stack...), StackElement{Type: Int, Value: 2})
+	err = roll.Roll()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(roll.ExecStack, rot.ExecStack) {
+		t.Errorf("Expected 2 roll to match rot: %v, but got: %v", rot.ExecStack, roll.ExecStack)
+	}
+}
+
+func TestExecuteProgramRecoversFromPanics(t *testing.T) {
+	g := NewGorth(false, false)
+
+	// an Int element holding a string makes ADD_OP's type assertion panic
+	program := []StackElement{
+		{Type: Int, Value: "not an int"},
+		{Type: Int, Value: 1},
+		{Type: Operator, Value: ADD_OP},
+	}
+
+	err := g.ExecuteProgram(program)
+	if err == nil {
+		t.Fatal("Expected an error, but got nil")
+	}
+
+	var gorthErr *GorthError
+	if !errors.As(err, &gorthErr) {
+		t.Fatalf("Expected a GorthError, but got: %v", err)
+	}
+
+	if gorthErr.Kind != InternalError {
+		t.Errorf("Expected kind %v, but got %v", InternalError, gorthErr.Kind)
+	}
+
+	if !gorthErr.HasOperation || gorthErr.Operation != ADD_OP {
+		t.Errorf("Expected operation %v, but got %v", ADD_OP, gorthErr.Operation)
+	}
+
+	if gorthErr.Position != 2 {
+		t.Errorf("Expected position 2, but got %d", gorthErr.Position)
+	}
+
+	// ADD_OP popped both operands before it panicked
+	if gorthErr.StackDepth != 0 {
+		t.Errorf("Expected stack depth 0, but got %d", gorthErr.StackDepth)
+	}
+
+	expectedPrefix := "ERROR: internal error in + at position 2 with stack depth 0: "
+	if !strings.HasPrefix(err.Error(), expectedPrefix) {
+		t.Errorf("Expected error starting with %q, but got: %q", expectedPrefix, err)
+	}
+}
+
+func TestExecuteProgramRecoversFromNonOperatorPanics(t *testing.T) {
+	g := NewGorth(false, false)
+
+	// a List element holding a string makes copying the list panic
+	program := []StackElement{
+		{Type: Int, Value: 1},
+		{Type: List, Value: "oops"},
+	}
+
+	err := g.ExecuteProgram(program)
+
+	var gorthErr *GorthError
+	if !errors.As(err, &gorthErr) {
+		t.Fatalf("Expected a GorthError, but got: %v", err)
+	}
+
+	if gorthErr.HasOperation {
+		t.Errorf("Expected no operation, but got %v", gorthErr.Operation)
+	}
+
+	if !reflect.DeepEqual(gorthErr.Element, program[1]) {
+		t.Errorf("Expected element %v, but got %v", program[1], gorthErr.Element)
+	}
+
+	expectedPrefix := "ERROR: internal error in list(oops) at position 1 with stack depth 1: "
+	if !strings.HasPrefix(err.Error(), expectedPrefix) {
+		t.Errorf("Expected error starting with %q, but got: %q", expectedPrefix, err)
+	}
+}
+
+func TestAssertEqual(t *testing.T) {
+	var testCases = TestCase{
+		// Test asserting equal integers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Int, Value: 5},
+			},
+			expected:    []StackElement{},
+			expectedErr: nil,
+			title:       "Test asserting equal integers",
+		},
+		// Test asserting equal strings
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: String, Value: "a"},
+			},
+			expected:    []StackElement{},
+			expectedErr: nil,
+			title:       "Test asserting equal strings",
+		},
+		// Test asserting equal strings with other elements below them
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "a"},
+				{Type: String, Value: "a"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test asserting equal strings with other elements below them",
+		},
+		// Test asserting unequal strings with other elements below them
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: String, Value: "a"},
+				{Type: String, Value: "b"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expectedErr: errors.New("ERROR: assertion failed (expected a, got b)"),
+			title:       "Test asserting unequal strings with other elements below them",
+		},
+		// Test asserting equal mixed numbers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Float, Value: 5.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: nil,
+			title:       "Test asserting equal mixed numbers",
+		},
+		// Test asserting equal lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: nil,
+			title:       "Test asserting equal lists",
+		},
+		// Test asserting a variable
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 3},
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 3},
+			},
+			expected:    []StackElement{},
+			expectedErr: nil,
+			title:       "Test asserting a variable",
+		},
+		// Test asserting unequal integers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: assertion failed (expected 1, got 2)"),
+			title:       "Test asserting unequal integers",
+		},
+		// Test asserting values of different types
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "1"},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: assertion failed (expected 1, got 1)"),
+			title:       "Test asserting values of different types",
+		},
+		// Test asserting with too few elements
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test asserting with too few elements",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.AssertEqual()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestAssertMessage(t *testing.T) {
+	var testCases = TestCase{
+		// Test asserting equal integers with a message
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Int, Value: 5},
+				{Type: String, Value: "mismatch"},
+			},
+			expected:    []StackElement{},
+			expectedErr: nil,
+			title:       "Test asserting equal integers with a message",
+		},
+		// Test asserting equal strings with a message and other elements below them
+		{
+			stack: []StackElement{
+				{Type: String, Value: "x"},
+				{Type: String, Value: "a"},
+				{Type: String, Value: "a"},
+				{Type: String, Value: "mismatch"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "x"},
+			},
+			expectedErr: nil,
+			title:       "Test asserting equal strings with a message and other elements below them",
+		},
+		// Test asserting unequal integers with a message
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: String, Value: "mismatch"},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: assertion failed: mismatch (expected 1, got 2)"),
+			title:       "Test asserting unequal integers with a message",
+		},
+		// Test asserting values of different types with a message
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "1"},
+				{Type: String, Value: "mismatch"},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: assertion failed: mismatch (expected 1, got 1)"),
+			title:       "Test asserting values of different types with a message",
+		},
+		// Test asserting with a message that is not a string
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 1},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test asserting with a message that is not a string",
+		},
+		// Test asserting with a message and too few elements
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "mismatch"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "mismatch"},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test asserting with a message and too few elements",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.AssertMessage()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestEqualLists(t *testing.T) {
+	g := NewGorth(false, false)
+	g.ExecStack = []StackElement{
+		{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Float, Value: 2.0}}},
+		{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+		{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+		{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+	}
+
+	// lists of different lengths are not equal
+	err := g.Equal()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	val, _ := g.Pop()
+	if val.Value != false {
+		t.Errorf("Expected lists of different lengths to not be equal")
+	}
+
+	// elements are compared with EQUAL_OP semantics
+	g.ExecStack = g.ExecStack[:2]
+	err = g.Equal()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	val, _ = g.Pop()
+	if val.Value != true {
+		t.Errorf("Expected [ 1 2.0 ] to equal [ 1 2 ]")
+	}
+}
+
+func TestFill(t *testing.T) {
+	var testCases = TestCase{
+		// Test filling a string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "x"},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "x"},
+				{Type: String, Value: "x"},
+				{Type: String, Value: "x"},
+			},
+			expectedErr: nil,
+			title:       "Test filling a string",
+		},
+		// Test filling a list
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: nil,
+			title:       "Test filling a list",
+		},
+		// Test filling with a count of one
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.5},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 1.5},
+			},
+			expectedErr: nil,
+			title:       "Test filling with a count of one",
+		},
+		// Test filling with a count of zero removes the value
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "x"},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test filling with a count of zero removes the value",
+		},
+		// Test filling with a negative count
+		{
+			stack: []StackElement{
+				{Type: String, Value: "x"},
+				{Type: Int, Value: -1},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "x"},
+			},
+			expectedErr: errors.New("ERROR: cannot perform FILL_OP with a negative count"),
+			title:       "Test filling with a negative count",
+		},
+		// Test filling with a non integer count
+		{
+			stack: []StackElement{
+				{Type: String, Value: "x"},
+				{Type: String, Value: "3"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "x"},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test filling with a non integer count",
+		},
+		// Test filling an empty stack
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 3},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test filling an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Fill()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestFillRespectsMaxStackSize(t *testing.T) {
+	g := NewGorth(false, false)
+	g.MaxStackSize = 5
+	g.ExecStack = []StackElement{
+		{Type: Int, Value: 1},
+		{Type: String, Value: "x"},
+		{Type: Int, Value: 5},
+	}
+
+	err := g.Fill()
+	if err == nil || err.Error() != "ERROR: stack overflow" {
+		t.Errorf("Expected error: %q, but got: %v", "ERROR: stack overflow", err)
+	}
+
+	// an overflowing fill leaves the stack as it was
+	expected := []StackElement{
+		{Type: Int, Value: 1},
+		{Type: String, Value: "x"},
+		{Type: Int, Value: 5},
+	}
+	if !reflect.DeepEqual(g.ExecStack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+	}
+}
+
+func TestFillOverflowKeepsVariableCount(t *testing.T) {
+	g := NewGorth(false, false)
+	g.MaxStackSize = 4
+	g.VariableMap = map[string]Variable{"n": {Name: "n", Type: Int, Value: 9}}
+	g.ExecStack = []StackElement{
+		{Type: String, Value: "x"},
+		{Type: Identifier, Value: "n"},
+	}
+
+	err := g.Fill()
+	if !errors.Is(err, ErrStackOverflow) {
+		t.Errorf("Expected error: %q, but got: %v", ErrStackOverflow, err)
+	}
+
+	expected := []StackElement{
+		{Type: String, Value: "x"},
+		{Type: Identifier, Value: "n"},
+	}
+	if !reflect.DeepEqual(g.ExecStack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+	}
+}
+
+func TestMean(t *testing.T) {
+	var testCases = TestCase{
+		// Test mean of integers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 6},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 3.0},
+			},
+			expectedErr: nil,
+			title:       "Test mean of integers",
+		},
+		// Test mean of mixed numbers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Float, Value: 2.5},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 2.1666666666666665},
+			},
+			expectedErr: nil,
+			title:       "Test mean of mixed numbers",
+		},
+		// Test mean stops at a non numeric element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 100},
+				{Type: String, Value: "stop"},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 4},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 100},
+				{Type: String, Value: "stop"},
+				{Type: Float, Value: 3.0},
+			},
+			expectedErr: nil,
+			title:       "Test mean stops at a non numeric element",
+		},
+		// Test mean of a numeric variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 4},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 3.0},
+			},
+			expectedErr: nil,
+			title:       "Test mean of a numeric variable",
+		},
+		// Test mean with no numeric elements
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expectedErr: errors.New("ERROR: no numeric elements on top of the stack to perform MEAN_OP"),
+			title:       "Test mean with no numeric elements",
+		},
+		// Test mean of an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: no numeric elements on top of the stack to perform MEAN_OP"),
+			title:       "Test mean of an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Mean()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	var testCases = TestCase{
+		// Test between at the low bound
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test between at the low bound",
+		},
+		// Test between at the high bound
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test between at the high bound",
+		},
+		// Test between inside the bounds
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test between inside the bounds",
+		},
+		// Test between below the bounds
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test between below the bounds",
+		},
+		// Test between above the bounds
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test between above the bounds",
+		},
+		// Test between with mixed numbers
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.5},
+				{Type: Int, Value: 1},
+				{Type: Float, Value: 2.0},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test between with mixed numbers",
+		},
+		// Test between at a float bound
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 2},
+				{Type: Float, Value: 0.5},
+				{Type: Float, Value: 2.0},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test between at a float bound",
+		},
+		// Test between with a variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 5},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Float, Value: 5.5},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test between with a variable",
+		},
+		// Test between with a non numeric value
+		{
+			stack: []StackElement{
+				{Type: String, Value: "3"},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 5},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test between with a non numeric value",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Between()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestClearNums(t *testing.T) {
+	var testCases = TestCase{
+		// Test clearing numbers above a string
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "keep"},
+				{Type: Int, Value: 2},
+				{Type: Float, Value: 3.5},
+				{Type: Int, Value: 4},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "keep"},
+			},
+			expectedErr: nil,
+			title:       "Test clearing numbers above a string",
+		},
+		// Test clearing a numeric variable
+		{
+			stack: []StackElement{
+				{Type: String, Value: "keep"},
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 4},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "keep"},
+			},
+			expectedErr: nil,
+			title:       "Test clearing a numeric variable",
+		},
+		// Test clearing stops at an undeclared variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "y"},
+				{Type: Int, Value: 4},
+			},
+			expected: []StackElement{
+				{Type: Identifier, Value: "y"},
+			},
+			expectedErr: nil,
+			title:       "Test clearing stops at an undeclared variable",
+		},
+		// Test clearing a non numeric top
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Bool, Value: true},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test clearing a non numeric top",
+		},
+		// Test clearing only numbers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected:    []StackElement{},
+			expectedErr: nil,
+			title:       "Test clearing only numbers",
+		},
+		// Test clearing an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: nil,
+			title:       "Test clearing an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			g.ClearNums()
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	var testCases = TestCase{
+		// Test LOAD of a register that has not been set
+		{
+			stack: []StackElement{
+				{Type: String, Value: "r1"},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: register r1 has not been set"),
+			title:       "Test LOAD of a register that has not been set",
+		},
+		// Test LOAD with a non string register name
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LOAD with a non string register name",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Load()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestStore(t *testing.T) {
+	var testCases = TestCase{
+		// Test STORE pops the value and the register name
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 42},
+				{Type: String, Value: "r1"},
+			},
+			expected:    []StackElement{},
+			expectedErr: nil,
+			title:       "Test STORE pops the value and the register name",
+		},
+		// Test STORE with a non string register name
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 42},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 42},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test STORE with a non string register name",
+		},
+		// Test STORE with a single element
+		{
+			stack: []StackElement{
+				{Type: String, Value: "r1"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "r1"},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test STORE with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Store()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestStoreAndLoad(t *testing.T) {
+	program, variables, err := Tokenize(`42 "r1" store 7 "r1" load`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g := NewGorth(false, false)
+	g.VariableMap = variables
+	if err := g.ExecuteProgram(program); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: Int, Value: 7}, {Type: Int, Value: 42}}
+	if !reflect.DeepEqual(g.ExecStack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+	}
+
+	if !reflect.DeepEqual(g.Registers["r1"], StackElement{Type: Int, Value: 42}) {
+		t.Errorf("Expected register r1 to hold 42, but got: %v", g.Registers["r1"])
+	}
+}
+
+func TestIf(t *testing.T) {
+	testCases := []struct {
+		title       string
+		source      string
+		expected    []StackElement
+		expectedErr error
+	}{
+		{
+			title:    "Test IF runs the if branch when true",
+			source:   `true if 1 else 2 end`,
+			expected: []StackElement{{Type: Int, Value: 1}},
+		},
+		{
+			title:    "Test IF runs the else branch when false",
+			source:   `false if 1 else 2 end`,
+			expected: []StackElement{{Type: Int, Value: 2}},
+		},
+		{
+			title:    "Test IF without else when true",
+			source:   `5 true if 1 + end`,
+			expected: []StackElement{{Type: Int, Value: 6}},
+		},
+		{
+			title:    "Test IF without else when false",
+			source:   `5 false if 1 + end`,
+			expected: []StackElement{{Type: Int, Value: 5}},
+		},
+		{
+			title:    "Test IF with a comparison as the condition",
+			source:   `3 4 < if "less" else "more" end`,
+			expected: []StackElement{{Type: String, Value: "less"}},
+		},
+		{
+			title:    "Test IF with a bool variable as the condition",
+			source:   `/x true def _x if 1 else 2 end`,
+			expected: []StackElement{{Type: Identifier, Value: "x"}, {Type: Int, Value: 1}},
+		},
+		{
+			title:    "Test nested IF inside the if branch",
+			source:   `true if false if 1 else 2 end else 3 end`,
+			expected: []StackElement{{Type: Int, Value: 2}},
+		},
+		{
+			title:    "Test nested IF inside a skipped branch",
+			source:   `false if true if 1 else 2 end else 3 end`,
+			expected: []StackElement{{Type: Int, Value: 3}},
+		},
+		{
+			title:    "Test nested IF inside the else branch",
+			source:   `false if 1 else true if 2 end 3 end`,
+			expected: []StackElement{{Type: Int, Value: 2}, {Type: Int, Value: 3}},
+		},
+		{
+			title:       "Test IF with a non bool condition",
+			source:      `1 if 2 end`,
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+		},
+		{
+			title:       "Test IF with an empty stack",
+			source:      `if 2 end`,
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+		},
+		{
+			title:       "Test IF without a matching end",
+			source:      `true if 1`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: if without a matching end"),
+		},
+		{
+			title:       "Test ELSE without a matching if",
+			source:      `true 1 else 2 end`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: else without a matching if"),
+		},
+		{
+			title:       "Test END without a matching if",
+			source:      `1 end`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: end without a matching if, while or scope"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			program, variables, err := Tokenize(tc.source)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			g := NewGorth(false, false)
+			g.VariableMap = variables
+
+			err = g.ExecuteProgram(program)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestSwapRegs(t *testing.T) {
+	var testCases = TestCase{
+		// Test SWAPREGS with an unset register
+		{
+			stack: []StackElement{
+				{Type: String, Value: "r1"},
+				{Type: String, Value: "r3"},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: register r3 has not been set"),
+			title:       "Test SWAPREGS with an unset register",
+		},
+		// Test SWAPREGS with a non string register name
+		{
+			stack: []StackElement{
+				{Type: String, Value: "r1"},
+				{Type: Int, Value: 2},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test SWAPREGS with a non string register name",
+		},
+		// Test SWAPREGS with a single element
+		{
+			stack: []StackElement{
+				{Type: String, Value: "r1"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "r1"},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test SWAPREGS with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			g.Registers["r1"] = StackElement{Type: Int, Value: 1}
+
+			err := g.SwapRegs()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestSwapRegsProgram(t *testing.T) {
+	program, variables, err := Tokenize(`1 "r1" store "two" "r2" store "r1" "r2" swapregs "r1" load "r2" load`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g := NewGorth(false, false)
+	g.VariableMap = variables
+	if err := g.ExecuteProgram(program); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: String, Value: "two"}, {Type: Int, Value: 1}}
+	if !reflect.DeepEqual(g.ExecStack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+	}
+}
+
+func TestWhileCountdown(t *testing.T) {
+	program, variables, err := Tokenize(`5 while dup 0 > do print 1 - end drop`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g := NewGorth(false, false)
+	g.VariableMap = variables
+
+	var out bytes.Buffer
+	g.Out = &out
+
+	err = g.ExecuteProgram(program)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedOutput := "5\n4\n3\n2\n1\n"
+	actualOutput := out.String()
+	if actualOutput != expectedOutput {
+		t.Errorf("Expected output: %q, but got: %q", expectedOutput, actualOutput)
+	}
+
+	if len(g.ExecStack) != 0 {
+		t.Errorf("Expected an empty stack, but got: %v", g.ExecStack)
+	}
+}
+
+func TestWhile(t *testing.T) {
+	testCases := []struct {
+		title       string
+		source      string
+		maxLoops    int
+		expected    []StackElement
+		expectedErr error
+	}{
+		{
+			title:    "Test WHILE that never runs its body",
+			source:   `0 while dup 0 > do 1 - end`,
+			expected: []StackElement{{Type: Int, Value: 0}},
+		},
+		{
+			title:    "Test WHILE summing a countdown",
+			source:   `0 3 while dup 0 > do dup -rot + swap 1 - end drop`,
+			expected: []StackElement{{Type: Int, Value: 6}},
+		},
+		{
+			title:    "Test nested WHILE loops",
+			source:   `0 2 while dup 0 > do 2 while dup 0 > do rot 1 + -rot 1 - end drop 1 - end drop`,
+			expected: []StackElement{{Type: Int, Value: 4}},
+		},
+		{
+			title:    "Test IF inside a WHILE body",
+			source:   `3 while dup 0 > do dup 2 == if "two" swap end 1 - end drop`,
+			expected: []StackElement{{Type: String, Value: "two"}},
+		},
+		{
+			title:       "Test WHILE with a non bool condition",
+			source:      `1 while 1 do end`,
+			expected:    []StackElement{{Type: Int, Value: 1}},
+			expectedErr: ErrTypeMismatch,
+		},
+		{
+			title:       "Test WHILE exceeding the iteration limit",
+			source:      `while true do end`,
+			maxLoops:    10,
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: loop exceeded the maximum of 10 iterations"),
+		},
+		{
+			title:       "Test WHILE without a matching do",
+			source:      `while true end`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: while without a matching do"),
+		},
+		{
+			title:       "Test WHILE without a matching end",
+			source:      `while true do`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: do without a matching end"),
+		},
+		{
+			title:       "Test DO without a matching while",
+			source:      `true do end`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: do without a matching while"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			program, variables, err := Tokenize(tc.source)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			g := NewGorth(false, false)
+			g.VariableMap = variables
+			if tc.maxLoops > 0 {
+				g.MaxLoopIterations = tc.maxLoops
+			}
+
+			err = g.ExecuteProgram(program)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestIsNaN(t *testing.T) {
+	var testCases = TestCase{
+		// Test NAN? on NaN
+		{
+			stack: []StackElement{
+				{Type: Float, Value: math.NaN()},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test NAN? on NaN",
+		},
+		// Test NAN? on a normal float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.5},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test NAN? on a normal float",
+		},
+		// Test NAN? on infinity
+		{
+			stack: []StackElement{
+				{Type: Float, Value: math.Inf(1)},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test NAN? on infinity",
+		},
+		// Test NAN? on a non float type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test NAN? on a non float type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.IsNaN()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestIsInf(t *testing.T) {
+	var testCases = TestCase{
+		// Test INF? on positive infinity
+		{
+			stack: []StackElement{
+				{Type: Float, Value: math.Inf(1)},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test INF? on positive infinity",
+		},
+		// Test INF? on negative infinity
+		{
+			stack: []StackElement{
+				{Type: Float, Value: math.Inf(-1)},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test INF? on negative infinity",
+		},
+		// Test INF? on a normal float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.5},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test INF? on a normal float",
+		},
+		// Test INF? on NaN
+		{
+			stack: []StackElement{
+				{Type: Float, Value: math.NaN()},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test INF? on NaN",
+		},
+		// Test INF? on a non float type
+		{
+			stack: []StackElement{
+				{Type: String, Value: "inf"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test INF? on a non float type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.IsInf()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestRejectNonFinite(t *testing.T) {
+	testCases := []struct {
+		title           string
+		stack           []StackElement
+		operation       func(g *Gorth) error
+		rejectNonFinite bool
+		expected        []StackElement
+		expectedErr     error
+	}{
+		// division by zero is rejected in both modes before a non-finite result can be produced
+		{
+			title:       "Test 1.0 0.0 / without RejectNonFinite",
+			stack:       []StackElement{{Type: Float, Value: 1.0}, {Type: Float, Value: 0.0}},
+			operation:   (*Gorth).Div,
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+		},
+		{
+			title:           "Test 1.0 0.0 / with RejectNonFinite",
+			stack:           []StackElement{{Type: Float, Value: 1.0}, {Type: Float, Value: 0.0}},
+			operation:       (*Gorth).Div,
+			rejectNonFinite: true,
+			expected:        []StackElement{},
+			expectedErr:     errors.New("ERROR: cannot divide by zero"),
+		},
+		{
+			title:     "Test an overflowing EXP_OP without RejectNonFinite",
+			stack:     []StackElement{{Type: Float, Value: 10.0}, {Type: Float, Value: 400.0}},
+			operation: (*Gorth).Exp,
+			expected:  []StackElement{{Type: Float, Value: math.Inf(1)}},
+		},
+		{
+			title:           "Test an overflowing EXP_OP with RejectNonFinite",
+			stack:           []StackElement{{Type: Float, Value: 10.0}, {Type: Float, Value: 400.0}},
+			operation:       (*Gorth).Exp,
+			rejectNonFinite: true,
+			expected:        []StackElement{},
+			expectedErr:     errors.New("ERROR: non-finite result in EXP_OP"),
+		},
+		{
+			title:           "Test an overflowing MUL_OP with RejectNonFinite",
+			stack:           []StackElement{{Type: Float, Value: 1e308}, {Type: Int, Value: 10}},
+			operation:       (*Gorth).Mul,
+			rejectNonFinite: true,
+			expected:        []StackElement{},
+			expectedErr:     errors.New("ERROR: non-finite result in MUL_OP"),
+		},
+		{
+			title:           "Test an overflowing ADD_OP with RejectNonFinite",
+			stack:           []StackElement{{Type: Float, Value: math.MaxFloat64}, {Type: Float, Value: math.MaxFloat64}},
+			operation:       (*Gorth).Add,
+			rejectNonFinite: true,
+			expected:        []StackElement{},
+			expectedErr:     errors.New("ERROR: non-finite result in ADD_OP"),
+		},
+		{
+			title:           "Test an overflowing SUB_OP with RejectNonFinite",
+			stack:           []StackElement{{Type: Float, Value: -math.MaxFloat64}, {Type: Float, Value: math.MaxFloat64}},
+			operation:       (*Gorth).Sub,
+			rejectNonFinite: true,
+			expected:        []StackElement{},
+			expectedErr:     errors.New("ERROR: non-finite result in SUB_OP"),
+		},
+		{
+			title:           "Test a finite result with RejectNonFinite",
+			stack:           []StackElement{{Type: Float, Value: 1.0}, {Type: Float, Value: 4.0}},
+			operation:       (*Gorth).Div,
+			rejectNonFinite: true,
+			expected:        []StackElement{{Type: Float, Value: 0.25}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.RejectNonFinite = tc.rejectNonFinite
+
+			err := tc.operation(g)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestProcedures(t *testing.T) {
+	testCases := []struct {
+		title        string
+		source       string
+		maxCallDepth int
+		expected     []StackElement
+		expectedErr  error
+	}{
+		{
+			title:    "Test defining and calling a square procedure",
+			source:   `def square dup * end 5 square`,
+			expected: []StackElement{{Type: Int, Value: 25}},
+		},
+		{
+			title:    "Test calling a procedure more than once",
+			source:   `def square dup * end 2 square square`,
+			expected: []StackElement{{Type: Int, Value: 16}},
+		},
+		{
+			title:    "Test defining a procedure does not run its body",
+			source:   `def square dup * end`,
+			expected: []StackElement{},
+		},
+		{
+			title:    "Test a procedure calling another procedure",
+			source:   `def square dup * end def quad square square end 3 quad`,
+			expected: []StackElement{{Type: Int, Value: 81}},
+		},
+		{
+			title:    "Test a recursive procedure with an if block",
+			source:   `def fact dup 1 > if dup 1 - fact * end end 5 fact`,
+			expected: []StackElement{{Type: Int, Value: 120}},
+		},
+		{
+			title:    "Test a procedure with a while loop",
+			source:   `def countdown while dup 0 > do 1 - end end 3 countdown`,
+			expected: []StackElement{{Type: Int, Value: 0}},
+		},
+		{
+			title:    "Test a variable declaration inside a procedure",
+			source:   `def double /x 21 def _x + end double`,
+			expected: []StackElement{{Type: Int, Value: 42}},
+		},
+		{
+			title:        "Test runaway recursion hits the call depth limit",
+			source:       `def forever forever end forever`,
+			maxCallDepth: 10,
+			expected:     []StackElement{},
+			expectedErr:  errors.New("ERROR: maximum call depth of 10 exceeded"),
+		},
+		{
+			title:       "Test calling a procedure whose definition was skipped",
+			source:      `false if def one 1 end end one`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: procedure one has not been defined"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			program, variables, err := Tokenize(tc.source)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			g := NewGorth(false, false)
+			g.VariableMap = variables
+			if tc.maxCallDepth > 0 {
+				g.MaxCallDepth = tc.maxCallDepth
+			}
+
+			err = g.ExecuteProgram(program)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestTokenizeProcedures(t *testing.T) {
+	program, _, err := Tokenize(`def square dup * end 5 square`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{
+		{Type: KeyWord, Value: Procedure{Name: "square", Body: []StackElement{
+			{Type: Operator, Value: DUP_OP},
+			{Type: Operator, Value: MUL_OP},
+		}}},
+		{Type: Int, Value: 5},
+		{Type: KeyWord, Value: "square"},
+	}
+	if !reflect.DeepEqual(program, expected) {
+		t.Errorf("Expected program: %v, but got: %v", expected, program)
+	}
+
+	errorCases := []struct {
+		source      string
+		expectedErr string
+	}{
+		{`square`, "invalid token: square"},
+		{`def 5 end`, "ERROR: invalid procedure name: 5"},
+		{`def dup 1 end`, "ERROR: invalid procedure name: dup"},
+		{`def square dup *`, "ERROR: procedure square is missing its end"},
+		{`def outer def inner end end`, "ERROR: cannot define a procedure inside another procedure"},
+		{`def`, "ERROR: def without a procedure name"},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.source, func(t *testing.T) {
+			_, _, err := Tokenize(tc.source)
+			if err == nil {
+				t.Fatalf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+			if err.Error() != tc.expectedErr {
+				t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	testCases := []struct {
+		title       string
+		input       string
+		expected    []StackElement
+		expectedErr error
+	}{
+		{
+			title:    "Test READ of an integer",
+			input:    "42\n",
+			expected: []StackElement{{Type: Int, Value: 42}},
+		},
+		{
+			title:    "Test READ of a float",
+			input:    "3.14\n",
+			expected: []StackElement{{Type: Float, Value: 3.14}},
+		},
+		{
+			title:    "Test READ of a bool",
+			input:    "true\n",
+			expected: []StackElement{{Type: Bool, Value: true}},
+		},
+		{
+			title:    "Test READ of a string",
+			input:    "hello\n",
+			expected: []StackElement{{Type: String, Value: "hello"}},
+		},
+		{
+			title:    "Test READ of a line without a trailing newline",
+			input:    "hello world",
+			expected: []StackElement{{Type: String, Value: "hello world"}},
+		},
+		{
+			title:    "Test READ strips a windows line ending",
+			input:    "7\r\n",
+			expected: []StackElement{{Type: Int, Value: 7}},
+		},
+		{
+			title:       "Test READ with no input left",
+			input:       "",
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: no input left to perform READ_OP"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.Input = strings.NewReader(tc.input)
+
+			err := g.Read()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestReadMultipleLines(t *testing.T) {
+	program, variables, err := Tokenize(`read read +`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g := NewGorth(false, false)
+	g.VariableMap = variables
+	g.Input = strings.NewReader("40\n2\n")
+
+	if err := g.ExecuteProgram(program); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: Int, Value: 42}}
+	if !reflect.DeepEqual(g.ExecStack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+	}
+}
+
+func TestHead(t *testing.T) {
+	var testCases = TestCase{
+		// Test HEAD on a populated list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "two"}, {Type: List, Value: []StackElement{{Type: Int, Value: 3}}}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "two"}, {Type: List, Value: []StackElement{{Type: Int, Value: 3}}}}},
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test HEAD on a populated list",
+		},
+		// Test HEAD on a single element list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "only"}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "only"}}},
+				{Type: String, Value: "only"},
+			},
+			expectedErr: nil,
+			title:       "Test HEAD on a single element list",
+		},
+		// Test HEAD on a list variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "xs"},
+			},
+			variableMap: map[string]Variable{
+				"xs": {Name: "xs", Type: List, Value: []StackElement{{Type: Int, Value: 7}, {Type: Int, Value: 8}}},
+			},
+			expected: []StackElement{
+				{Type: Identifier, Value: "xs"},
+				{Type: Int, Value: 7},
+			},
+			expectedErr: nil,
+			title:       "Test HEAD on a list variable",
+		},
+		// Test HEAD on an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: errors.New("ERROR: cannot perform HEAD_OP on an empty list"),
+			title:       "Test HEAD on an empty list",
+		},
+		// Test HEAD on a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test HEAD on a non list type",
+		},
+		// Test HEAD on an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test HEAD on an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Head()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLast(t *testing.T) {
+	var testCases = TestCase{
+		// Test LAST on a populated list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "two"}, {Type: List, Value: []StackElement{{Type: Int, Value: 3}}}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "two"}, {Type: List, Value: []StackElement{{Type: Int, Value: 3}}}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 3}}},
+			},
+			expectedErr: nil,
+			title:       "Test LAST on a populated list",
+		},
+		// Test LAST on a single element list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "only"}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "only"}}},
+				{Type: String, Value: "only"},
+			},
+			expectedErr: nil,
+			title:       "Test LAST on a single element list",
+		},
+		// Test LAST on an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: errors.New("ERROR: cannot perform LAST_OP on an empty list"),
+			title:       "Test LAST on an empty list",
+		},
+		// Test LAST on a non list type
+		{
+			stack: []StackElement{
+				{Type: String, Value: "abc"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "abc"},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LAST on a non list type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Last()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLastCopiesLists(t *testing.T) {
+	inner := []StackElement{{Type: Int, Value: 1}}
+	g := NewGorth(false, false)
+	g.ExecStack = []StackElement{{Type: List, Value: []StackElement{{Type: List, Value: inner}}}}
+
+	if err := g.Last(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g.ExecStack[1].Value.([]StackElement)[0] = StackElement{Type: Int, Value: 2}
+	if inner[0].Value != 1 {
+		t.Errorf("Expected the list inside the original list to be unchanged, but got: %v", inner)
+	}
+}
+
+func TestTail(t *testing.T) {
+	var testCases = TestCase{
+		// Test TAIL on a multi element list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expectedErr: nil,
+			title:       "Test TAIL on a multi element list",
+		},
+		// Test TAIL on a single element list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test TAIL on a single element list",
+		},
+		// Test TAIL on an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot perform TAIL_OP on an empty list"),
+			title:       "Test TAIL on an empty list",
+		},
+		// Test TAIL on a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test TAIL on a non list type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Tail()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		title       string
+		source      string
+		strict      bool
+		expected    []StackElement
+		expectedErr error
+	}{
+		{
+			title:    "Test RUN of an arithmetic program",
+			source:   `1 2 + 3 *`,
+			expected: []StackElement{{Type: Int, Value: 9}},
+		},
+		{
+			title:    "Test RUN of a program with a variable",
+			source:   `/x 5 def _x _x *`,
+			expected: []StackElement{{Type: Identifier, Value: "x"}, {Type: Int, Value: 25}},
+		},
+		{
+			title:    "Test RUN of a program with a procedure",
+			source:   `def square dup * end 4 square`,
+			expected: []StackElement{{Type: Int, Value: 16}},
+		},
+		{
+			title:    "Test RUN of a strict program that consumes its stack",
+			source:   `"Hello, World!" dump`,
+			strict:   true,
+			expected: []StackElement{},
+		},
+		{
+			title:       "Test RUN of a strict program that leaves values on the stack",
+			source:      `1 2`,
+			strict:      true,
+			expected:    []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}},
+			expectedErr: errors.New("ERROR: unconsumed elements remain on the stack\n\t[int(1) int(2)]"),
+		},
+		{
+			title:    "Test RUN of a program with # comments",
+			source:   "# adds two numbers\n1 2 # c\n3 +",
+			expected: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 5}},
+		},
+		{
+			title:       "Test RUN of a program declaring a variable twice",
+			source:      `/x 1 def /x 2 def _x`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("variable x has already been declared"),
+		},
+		{
+			title:    "Test RUN of a program reusing the name of a declared variable",
+			source:   `/x 1 def /x dup`,
+			expected: []StackElement{{Type: Identifier, Value: "x"}, {Type: Identifier, Value: "x"}},
+		},
+		{
+			title:       "Test RUN of a program with an invalid token",
+			source:      `1 nope`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("invalid token: nope"),
+		},
+		{
+			title:       "Test RUN of a program that fails while executing",
+			source:      `1 0 /`,
+			expected:    []StackElement{},
+			expectedErr: ErrDivideByZero,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			stack, err := Run(tc.source, false, tc.strict)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(stack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, stack)
+			}
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	g := NewGorth(false, false)
+	g.MaxStackSize = 100
+
+	if err := g.Run(`/x 5 def 1 2 42 "r1" store def square dup * end`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g.DebugMode = true
+	g.Reset()
+
+	if !reflect.DeepEqual(g.ExecStack, []StackElement{}) {
+		t.Errorf("Expected an empty stack, but got: %v", g.ExecStack)
+	}
+
+	if g.VariableMap == nil || len(g.VariableMap) != 0 {
+		t.Errorf("Expected an empty variable map, but got: %v", g.VariableMap)
+	}
+
+	if len(g.Registers) != 0 || len(g.Procedures) != 0 {
+		t.Errorf("Expected empty registers and procedures, but got: %v and %v", g.Registers, g.Procedures)
+	}
+
+	if !g.DebugMode || g.StrictMode || g.MaxStackSize != 100 {
+		t.Errorf("Expected options to be kept, but got debug %v, strict %v, max stack size %v", g.DebugMode, g.StrictMode, g.MaxStackSize)
+	}
+
+	// the instance can run another program after a reset
+	g.DebugMode = false
+	if err := g.Run(`3 4 +`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: Int, Value: 7}}
+	if !reflect.DeepEqual(g.ExecStack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+	}
+}
+
+func TestCons(t *testing.T) {
+	var testCases = TestCase{
+		// Test CONS onto a non empty list
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expectedErr: nil,
+			title:       "Test CONS onto a non empty list",
+		},
+		// Test CONS onto an empty list
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}}},
+			},
+			expectedErr: nil,
+			title:       "Test CONS onto an empty list",
+		},
+		// Test CONS of a list onto a list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}}}, {Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test CONS of a list onto a list",
+		},
+		// Test CONS of a variable onto a list
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}}},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 5}, {Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test CONS of a variable onto a list",
+		},
+		// Test CONS without a list on top
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test CONS without a list on top",
+		},
+		// Test CONS with a single element
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test CONS with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Cons()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestConsCopiesLists(t *testing.T) {
+	inner := []StackElement{{Type: Int, Value: 1}}
+	g := NewGorth(false, false)
+	g.ExecStack = []StackElement{{Type: List, Value: inner}, {Type: List, Value: []StackElement{}}}
+
+	if err := g.Cons(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g.ExecStack[0].Value.([]StackElement)[0].Value.([]StackElement)[0] = StackElement{Type: Int, Value: 2}
+	if inner[0].Value != 1 {
+		t.Errorf("Expected the prepended list to be copied, but got: %v", inner)
+	}
+}
+
+func TestDropDup(t *testing.T) {
+	var testCases = TestCase{
+		// Test DROPDUP with equal adjacent ints
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test DROPDUP with equal adjacent ints",
+		},
+		// Test DROPDUP with distinct adjacent ints
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test DROPDUP with distinct adjacent ints",
+		},
+		// Test DROPDUP with an int equal to a float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.0},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 1.0},
+			},
+			expectedErr: nil,
+			title:       "Test DROPDUP with an int equal to a float",
+		},
+		// Test DROPDUP with equal adjacent strings
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: String, Value: "a"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expectedErr: nil,
+			title:       "Test DROPDUP with equal adjacent strings",
+		},
+		// Test DROPDUP with distinct types
+		{
+			stack: []StackElement{
+				{Type: String, Value: "1"},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "1"},
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test DROPDUP with distinct types",
+		},
+		// Test DROPDUP with equal adjacent lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: nil,
+			title:       "Test DROPDUP with equal adjacent lists",
+		},
+		// Test DROPDUP with a variable equal to the value beneath it
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test DROPDUP with a variable equal to the value beneath it",
+		},
+		// Test DROPDUP with a single element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test DROPDUP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.DropDup()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestNewGorthVariableMap(t *testing.T) {
+	g := NewGorth(false, false)
+	if g.VariableMap == nil {
+		t.Fatal("Expected NewGorth to initialize VariableMap")
+	}
+
+	g.VariableMap["x"] = Variable{Name: "x", Type: Int, Value: 5}
+	program := []StackElement{
+		{Type: Identifier, Value: "x"},
+		{Type: Int, Value: 10},
+		{Type: Operator, Value: VAR_ASSIGN_OP},
+	}
+
+	if err := g.ExecuteProgram(program); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := Variable{Name: "x", Type: Int, Value: 10}
+	if !reflect.DeepEqual(g.VariableMap["x"], expected) {
+		t.Errorf("Expected variable: %v, but got: %v", expected, g.VariableMap["x"])
+	}
+}
+
+func TestRunMergesVariables(t *testing.T) {
+	g := NewGorth(false, false)
+	g.VariableMap["y"] = Variable{Name: "y", Type: String, Value: "kept"}
+
+	if err := g.Run(`/x 5 def`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]Variable{
+		"x": {Name: "x", Type: Int, Value: 5},
+		"y": {Name: "y", Type: String, Value: "kept"},
+	}
+	if !reflect.DeepEqual(g.VariableMap, expected) {
+		t.Errorf("Expected variables: %v, but got: %v", expected, g.VariableMap)
+	}
+}
+
+func TestCommas(t *testing.T) {
+	var testCases = TestCase{
+		// Test COMMAS on a large int
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1000000},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "1,000,000"},
+			},
+			expectedErr: nil,
+			title:       "Test COMMAS on a large int",
+		},
+		// Test COMMAS on an int with an uneven first group
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1234567},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "1,234,567"},
+			},
+			expectedErr: nil,
+			title:       "Test COMMAS on an int with an uneven first group",
+		},
+		// Test COMMAS on a small int
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 999},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "999"},
+			},
+			expectedErr: nil,
+			title:       "Test COMMAS on a small int",
+		},
+		// Test COMMAS on zero
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "0"},
+			},
+			expectedErr: nil,
+			title:       "Test COMMAS on zero",
+		},
+		// Test COMMAS on a negative int
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -1234567},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "-1,234,567"},
+			},
+			expectedErr: nil,
+			title:       "Test COMMAS on a negative int",
+		},
+		// Test COMMAS on a negative int with three digits
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -123},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "-123"},
+			},
+			expectedErr: nil,
+			title:       "Test COMMAS on a negative int with three digits",
+		},
+		// Test COMMAS on a float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1234567.891},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "1,234,567.891"},
+			},
+			expectedErr: nil,
+			title:       "Test COMMAS on a float",
+		},
+		// Test COMMAS on a negative float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: -1234.5},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "-1,234.5"},
+			},
+			expectedErr: nil,
+			title:       "Test COMMAS on a negative float",
+		},
+		// Test COMMAS on a whole float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1000.0},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "1,000"},
+			},
+			expectedErr: nil,
+			title:       "Test COMMAS on a whole float",
+		},
+		// Test COMMAS on a variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 12345},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "12,345"},
+			},
+			expectedErr: nil,
+			title:       "Test COMMAS on a variable",
+		},
+		// Test COMMAS on infinity
+		{
+			stack: []StackElement{
+				{Type: Float, Value: math.Inf(1)},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot perform COMMAS_OP on a non-finite float"),
+			title:       "Test COMMAS on infinity",
+		},
+		// Test COMMAS on a non numeric type
+		{
+			stack: []StackElement{
+				{Type: String, Value: "1000"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test COMMAS on a non numeric type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Commas()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestStackCount(t *testing.T) {
+	var testCases = TestCase{
+		// Test STACKCOUNT with several equal and unequal elements
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Int, Value: 1},
+				{Type: Float, Value: 5.0},
+				{Type: String, Value: "5"},
+				{Type: Int, Value: 5},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+				{Type: Int, Value: 1},
+				{Type: Float, Value: 5.0},
+				{Type: String, Value: "5"},
+				{Type: Int, Value: 5},
+				{Type: Int, Value: 3},
+			},
+			expectedErr: nil,
+			title:       "Test STACKCOUNT with several equal and unequal elements",
+		},
+		// Test STACKCOUNT with no equal elements
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test STACKCOUNT with no equal elements",
+		},
+		// Test STACKCOUNT with only the query value
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test STACKCOUNT with only the query value",
+		},
+		// Test STACKCOUNT of a variable
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 7},
+				{Type: Identifier, Value: "x"},
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 7},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 7},
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test STACKCOUNT of a variable",
+		},
+		// Test STACKCOUNT on an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test STACKCOUNT on an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.StackCount()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	testCases := []struct {
+		title    string
+		source   string
+		sentinel error
+		message  string
+	}{
+		{
+			title:    "Test popping an empty stack wraps ErrStackEmpty",
+			source:   `+`,
+			sentinel: ErrStackEmpty,
+			message:  "ERROR: cannot pop from an empty stack",
+		},
+		{
+			title:    "Test too few elements wraps ErrStackEmpty",
+			source:   `1 2 rot`,
+			sentinel: ErrStackEmpty,
+			message:  "ERROR: at least 3 elements need to be on stack to perform ROT_OP",
+		},
+		{
+			title:    "Test dividing by zero wraps ErrDivideByZero",
+			source:   `1 0 /`,
+			sentinel: ErrDivideByZero,
+			message:  "ERROR: cannot divide by zero",
+		},
+		{
+			title:    "Test mixing types wraps ErrTypeMismatch",
+			source:   `"a" 1 -`,
+			sentinel: ErrTypeMismatch,
+			message:  "ERROR: cannot perform SUB_OP on different types",
+		},
+		{
+			title:    "Test a non bool condition wraps ErrTypeMismatch",
+			source:   `1 if end`,
+			sentinel: ErrTypeMismatch,
+			message:  "ERROR: if expects a Bool on top of the stack",
+		},
+		{
+			title:    "Test an undeclared variable wraps ErrUndeclaredVariable",
+			source:   `_x`,
+			sentinel: ErrUndeclaredVariable,
+			message:  "variable x has not been declared",
+		},
+		{
+			title:    "Test filling past the stack size wraps ErrStackOverflow",
+			source:   `0 1000000000 fill`,
+			sentinel: ErrStackOverflow,
+			message:  "ERROR: stack overflow",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			_, err := Run(tc.source, false, false)
+			if !errors.Is(err, tc.sentinel) {
+				t.Errorf("Expected error wrapping %q, but got: %v", tc.sentinel, err)
+			}
+
+			// wrapping a sentinel does not change the message
+			if err != nil && err.Error() != tc.message {
+				t.Errorf("Expected error: %q, but got: %q", tc.message, err)
+			}
+		})
+	}
+}
+
+func TestSentinelErrorsAreDistinct(t *testing.T) {
+	g := NewGorth(false, false)
+	g.ExecStack = []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 0}}
+
+	err := g.Div()
+	if !errors.Is(err, ErrDivideByZero) {
+		t.Fatalf("Expected error wrapping %q, but got: %v", ErrDivideByZero, err)
+	}
+
+	for _, sentinel := range []error{ErrStackOverflow, ErrStackEmpty, ErrTypeMismatch, ErrUndeclaredVariable} {
+		if errors.Is(err, sentinel) {
+			t.Errorf("Expected %q not to match %q", err, sentinel)
+		}
+	}
+}
+
+func TestToInt(t *testing.T) {
+	var testCases = TestCase{
+		// Test TOINT truncates a float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 3.9},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+			},
+			expectedErr: nil,
+			title:       "Test TOINT truncates a float",
+		},
+		// Test TOINT truncates a negative float towards zero
+		{
+			stack: []StackElement{
+				{Type: Float, Value: -3.9},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: -3},
+			},
+			expectedErr: nil,
+			title:       "Test TOINT truncates a negative float towards zero",
+		},
+		// Test TOINT parses a numeric string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "42"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 42},
+			},
+			expectedErr: nil,
+			title:       "Test TOINT parses a numeric string",
+		},
+		// Test TOINT parses a negative numeric string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "-7"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: -7},
+			},
+			expectedErr: nil,
+			title:       "Test TOINT parses a negative numeric string",
+		},
+		// Test TOINT passes an int through
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test TOINT passes an int through",
+		},
+		// Test TOINT on a float variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Float, Value: 2.5},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test TOINT on a float variable",
+		},
+		// Test TOINT on an unparseable string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "abc"},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot convert \"abc\" to an integer"),
+			title:       "Test TOINT on an unparseable string",
+		},
+		// Test TOINT on a float string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "3.9"},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot convert \"3.9\" to an integer"),
+			title:       "Test TOINT on a float string",
+		},
+		// Test TOINT on NaN
+		{
+			stack: []StackElement{
+				{Type: Float, Value: math.NaN()},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot convert NaN to an integer"),
+			title:       "Test TOINT on NaN",
+		},
+		// Test TOINT on a bool
+		{
+			stack: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test TOINT on a bool",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.ToInt()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestStackList(t *testing.T) {
+	var testCases = TestCase{
+		// Test STACKLIST keeps the elements beneath the list
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "two"},
+				{Type: Bool, Value: true},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: "two"},
+				{Type: Bool, Value: true},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "two"}, {Type: Bool, Value: true}}},
+			},
+			expectedErr: nil,
+			title:       "Test STACKLIST keeps the elements beneath the list",
+		},
+		// Test STACKLIST copies nested lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test STACKLIST copies nested lists",
+		},
+		// Test STACKLIST resolves variables
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 5}}},
+			},
+			expectedErr: nil,
+			title:       "Test STACKLIST resolves variables",
+		},
+		// Test STACKLIST on an empty stack
+		{
+			stack: []StackElement{},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test STACKLIST on an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.StackList()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestStackListCopiesElements(t *testing.T) {
+	inner := []StackElement{{Type: Int, Value: 1}}
+	g := NewGorth(false, false)
+	g.ExecStack = []StackElement{{Type: List, Value: inner}}
+
+	if err := g.StackList(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g.ExecStack[1].Value.([]StackElement)[0].Value.([]StackElement)[0] = StackElement{Type: Int, Value: 2}
+	if inner[0].Value != 1 {
+		t.Errorf("Expected the original list to be unchanged, but got: %v", inner)
+	}
+}
+
+func TestToFloat(t *testing.T) {
+	var testCases = TestCase{
+		// Test TOFLOAT converts an int
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 5.0},
+			},
+			expectedErr: nil,
+			title:       "Test TOFLOAT converts an int",
+		},
+		// Test TOFLOAT parses a numeric string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "2.5"},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 2.5},
+			},
+			expectedErr: nil,
+			title:       "Test TOFLOAT parses a numeric string",
+		},
+		// Test TOFLOAT parses an integer string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "-3"},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: -3.0},
+			},
+			expectedErr: nil,
+			title:       "Test TOFLOAT parses an integer string",
+		},
+		// Test TOFLOAT passes a float through
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.25},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 1.25},
+			},
+			expectedErr: nil,
+			title:       "Test TOFLOAT passes a float through",
+		},
+		// Test TOFLOAT on an int variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 7},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 7.0},
+			},
+			expectedErr: nil,
+			title:       "Test TOFLOAT on an int variable",
+		},
+		// Test TOFLOAT on an unparseable string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "abc"},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot convert \"abc\" to a float"),
+			title:       "Test TOFLOAT on an unparseable string",
+		},
+		// Test TOFLOAT on a bool
+		{
+			stack: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test TOFLOAT on a bool",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.ToFloat()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLShuffle(t *testing.T) {
+	list := StackElement{Type: List, Value: []StackElement{
+		{Type: Int, Value: 1},
+		{Type: Int, Value: 2},
+		{Type: Int, Value: 3},
+		{Type: Int, Value: 4},
+		{Type: Int, Value: 5},
+	}}
+
+	g := NewGorth(false, false)
+	g.ExecStack = []StackElement{list, {Type: Int, Value: 42}}
+
+	program := []StackElement{
+		{Type: Operator, Value: SEED_OP},
+		{Type: Operator, Value: LSHUFFLE_OP},
+	}
+	if err := g.ExecuteProgram(program); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: List, Value: []StackElement{
+		{Type: Int, Value: 3},
+		{Type: Int, Value: 4},
+		{Type: Int, Value: 5},
+		{Type: Int, Value: 1},
+		{Type: Int, Value: 2},
+	}}}
+	if !reflect.DeepEqual(g.ExecStack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+	}
+
+	// the original list is copied rather than shuffled in place
+	if list.Value.([]StackElement)[0].Value != 1 {
+		t.Errorf("Expected the original list to be unchanged, but got: %v", list)
+	}
+
+	// the same seed gives the same order again
+	g.ExecStack = []StackElement{list, {Type: Int, Value: 42}}
+	if err := g.ExecuteProgram(program); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(g.ExecStack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	var testCases = TestCase{
+		// Test SEED pops the seed
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 42},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test SEED pops the seed",
+		},
+		// Test SEED with a non integer seed
+		{
+			stack: []StackElement{
+				{Type: String, Value: "42"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test SEED with a non integer seed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Seed()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLShuffleErrors(t *testing.T) {
+	var testCases = TestCase{
+		// Test LSHUFFLE on an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test LSHUFFLE on an empty list",
+		},
+		// Test LSHUFFLE on a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LSHUFFLE on a non list type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.LShuffle()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestToStr(t *testing.T) {
+	var testCases = TestCase{
+		// Test TOSTR on an int
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 42},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "42"},
+			},
+			expectedErr: nil,
+			title:       "Test TOSTR on an int",
+		},
+		// Test TOSTR on a float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 3.14},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "3.14"},
+			},
+			expectedErr: nil,
+			title:       "Test TOSTR on a float",
+		},
+		// Test TOSTR on a bool
+		{
+			stack: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "true"},
+			},
+			expectedErr: nil,
+			title:       "Test TOSTR on a bool",
+		},
+		// Test TOSTR on a string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hi"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "hi"},
+			},
+			expectedErr: nil,
+			title:       "Test TOSTR on a string",
+		},
+		// Test TOSTR on a variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: -7},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "-7"},
+			},
+			expectedErr: nil,
+			title:       "Test TOSTR on a variable",
+		},
+		// Test TOSTR on a list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test TOSTR on a list",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.ToStr()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestToStrPushesStrings(t *testing.T) {
+	for _, source := range []string{`42 tostr`, `3.14 tostr`, `true tostr`} {
+		stack, err := Run(source, false, false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(stack) != 1 || stack[0].Type != String {
+			t.Errorf("Expected %s to push a single String, but got: %v", source, stack)
+		}
+	}
+}
+
+func TestReadFields(t *testing.T) {
+	testCases := []struct {
+		title       string
+		input       string
+		expected    []StackElement
+		expectedErr error
+	}{
+		{
+			title: "Test READFIELDS of mixed fields",
+			input: "10 3.5 hello true\n",
+			expected: []StackElement{
+				{Type: Int, Value: 10},
+				{Type: Float, Value: 3.5},
+				{Type: String, Value: "hello"},
+				{Type: Bool, Value: true},
+				{Type: Int, Value: 4},
+			},
+		},
+		{
+			title: "Test READFIELDS with extra whitespace",
+			input: "  -1 \t false  \n",
+			expected: []StackElement{
+				{Type: Int, Value: -1},
+				{Type: Bool, Value: false},
+				{Type: Int, Value: 2},
+			},
+		},
+		{
+			title:    "Test READFIELDS of an empty line",
+			input:    "\n",
+			expected: []StackElement{{Type: Int, Value: 0}},
+		},
+		{
+			title:       "Test READFIELDS with no input left",
+			input:       "",
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: no input left to perform READFIELDS_OP"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.Input = strings.NewReader(tc.input)
+
+			err := g.ReadFields()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestReturn(t *testing.T) {
+	testCases := []struct {
+		title    string
+		source   string
+		expected []StackElement
+	}{
+		{
+			title:    "Test RETURN exits a procedure early when the condition holds",
+			source:   `def clamp dup 10 > if drop 10 return end 1 + end 50 clamp`,
+			expected: []StackElement{{Type: Int, Value: 10}},
+		},
+		{
+			title:    "Test RETURN is skipped when the condition does not hold",
+			source:   `def clamp dup 10 > if drop 10 return end 1 + end 5 clamp`,
+			expected: []StackElement{{Type: Int, Value: 6}},
+		},
+		{
+			title:    "Test RETURN only exits the innermost procedure",
+			source:   `def inner 1 return 2 end def outer inner 3 end outer`,
+			expected: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 3}},
+		},
+		{
+			title:    "Test RETURN from inside a loop in a procedure",
+			source:   `def first while true do 7 return end end first 8`,
+			expected: []StackElement{{Type: Int, Value: 7}, {Type: Int, Value: 8}},
+		},
+		{
+			title:    "Test RETURN at the top level stops the program",
+			source:   `1 return 2`,
+			expected: []StackElement{{Type: Int, Value: 1}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			stack, err := Run(tc.source, false, false)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(stack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, stack)
+			}
+		})
+	}
+}
+
+func TestReturnAtTopLevelInStrictMode(t *testing.T) {
+	_, err := Run(`1 return drop`, false, true)
+
+	expectedErr := "ERROR: unconsumed elements remain on the stack\n\t[int(1)]"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected error: %q, but got: %v", expectedErr, err)
+	}
+}
+
+func TestTokenizeIntegerLiteralOverflow(t *testing.T) {
+	_, _, err := Tokenize("0x1FFFFFFFFFFFFFFFF")
+
+	expectedErr := "ERROR: invalid integer literal: 0x1FFFFFFFFFFFFFFFF"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected error: %q, but got: %v", expectedErr, err)
+	}
+}
+
+func TestBoolToStr(t *testing.T) {
+	var testCases = TestCase{
+		// Test BOOL2STR on true
+		{
+			stack: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "true"},
+			},
+			expectedErr: nil,
+			title:       "Test BOOL2STR on true",
+		},
+		// Test BOOL2STR on false
+		{
+			stack: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "false"},
+			},
+			expectedErr: nil,
+			title:       "Test BOOL2STR on false",
+		},
+		// Test BOOL2STR on a bool variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "ok"},
+			},
+			variableMap: map[string]Variable{
+				"ok": {Name: "ok", Type: Bool, Value: true},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "true"},
+			},
+			expectedErr: nil,
+			title:       "Test BOOL2STR on a bool variable",
+		},
+		// Test BOOL2STR on a non boolean type
+		{
+			stack: []StackElement{
+				{Type: String, Value: "true"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test BOOL2STR on a non boolean type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.BoolToStr()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestStrToBool(t *testing.T) {
+	var testCases = TestCase{
+		// Test STR2BOOL on true
+		{
+			stack: []StackElement{
+				{Type: String, Value: "true"},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test STR2BOOL on true",
+		},
+		// Test STR2BOOL on false
+		{
+			stack: []StackElement{
+				{Type: String, Value: "false"},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test STR2BOOL on false",
+		},
+		// Test STR2BOOL on 1
+		{
+			stack: []StackElement{
+				{Type: String, Value: "1"},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test STR2BOOL on 1",
+		},
+		// Test STR2BOOL on 0
+		{
+			stack: []StackElement{
+				{Type: String, Value: "0"},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test STR2BOOL on 0",
+		},
+		// Test STR2BOOL on an invalid string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "yes"},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot convert \"yes\" to a bool"),
+			title:       "Test STR2BOOL on an invalid string",
+		},
+		// Test STR2BOOL on a non string type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test STR2BOOL on a non string type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.StrToBool()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestBoolStrRoundTrip(t *testing.T) {
+	stack, err := Run(`true bool2str str2bool false bool2str str2bool`, false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: Bool, Value: true}, {Type: Bool, Value: false}}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, stack)
+	}
+}
+
+func TestDot(t *testing.T) {
+	var testCases = TestCase{
+		// Test DOT of int lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 4}, {Type: Int, Value: 5}, {Type: Int, Value: 6}}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 32},
+			},
+			expectedErr: nil,
+			title:       "Test DOT of int lists",
+		},
+		// Test DOT of mixed type lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Float, Value: 0.5}}},
+				{Type: List, Value: []StackElement{{Type: Float, Value: 2.0}, {Type: Int, Value: 4}}},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 4.0},
+			},
+			expectedErr: nil,
+			title:       "Test DOT of mixed type lists",
+		},
+		// Test DOT of empty lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test DOT of empty lists",
+		},
+		// Test DOT of lists with different lengths
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: DOT_OP expects lists of the same length, but got 2 and 1"),
+			title:       "Test DOT of lists with different lengths",
+		},
+		// Test DOT of lists with non numeric elements
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test DOT of lists with non numeric elements",
+		},
+		// Test DOT with a non list type
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test DOT with a non list type",
+		},
+		// Test DOT with a single element
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test DOT with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Dot()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestTabulate(t *testing.T) {
+	testCases := []struct {
+		title       string
+		source      string
+		expected    []StackElement
+		expectedErr error
+	}{
+		{
+			title:  "Test TABULATE of squares",
+			source: `5 { dup * } tabulate`,
+			expected: []StackElement{{Type: List, Value: []StackElement{
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 4},
+				{Type: Int, Value: 9},
+				{Type: Int, Value: 16},
+			}}},
+		},
+		{
+			title:    "Test TABULATE with a count of zero",
+			source:   `0 { dup * } tabulate`,
+			expected: []StackElement{{Type: List, Value: []StackElement{}}},
+		},
+		{
+			title:  "Test TABULATE leaves the elements beneath it alone",
+			source: `"keep" 3 { 10 + } tabulate`,
+			expected: []StackElement{
+				{Type: String, Value: "keep"},
+				{Type: List, Value: []StackElement{
+					{Type: Int, Value: 10},
+					{Type: Int, Value: 11},
+					{Type: Int, Value: 12},
+				}},
+			},
+		},
+		{
+			title:  "Test TABULATE with a procedure call and a nested if",
+			source: `def square dup * end 3 { square dup 1 > if "big" else "small" end swap drop } tabulate`,
+			expected: []StackElement{{Type: List, Value: []StackElement{
+				{Type: String, Value: "small"},
+				{Type: String, Value: "small"},
+				{Type: String, Value: "big"},
+			}}},
+		},
+		{
+			title:       "Test TABULATE with a negative count",
+			source:      `-1 { dup * } tabulate`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot perform TABULATE_OP with a negative count"),
+		},
+		{
+			title:       "Test TABULATE without a quotation",
+			source:      `5 5 tabulate`,
+			expected:    []StackElement{{Type: Int, Value: 5}},
+			expectedErr: ErrTypeMismatch,
+		},
+		{
+			title:       "Test TABULATE with a quotation that consumes too much",
+			source:      `1 { drop drop } tabulate`,
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			stack, err := Run(tc.source, false, false)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(stack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, stack)
+			}
+		})
+	}
+}
+
+func TestIfte(t *testing.T) {
+	testCases := []struct {
+		title       string
+		source      string
+		expected    []StackElement
+		expectedErr error
+	}{
+		{
+			title:    "Test IFTE with a true condition",
+			source:   `true { "yes" } { "no" } ifte`,
+			expected: []StackElement{{Type: String, Value: "yes"}},
+		},
+		{
+			title:    "Test IFTE with a false condition",
+			source:   `3 4 > { "greater" } { "not greater" } ifte`,
+			expected: []StackElement{{Type: String, Value: "not greater"}},
+		},
+		{
+			title:    "Test IFTE runs the branch against the stack",
+			source:   `5 dup 0 > { 1 + } { 1 - } ifte`,
+			expected: []StackElement{{Type: Int, Value: 6}},
+		},
+		{
+			title:    "Test IFTE with a nested ifte",
+			source:   `true { false { 1 } { 2 } ifte } { 3 } ifte`,
+			expected: []StackElement{{Type: Int, Value: 2}},
+		},
+		{
+			title:       "Test IFTE with a non bool condition",
+			source:      `1 { "yes" } { "no" } ifte`,
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+		},
+		{
+			title:       "Test IFTE without quotations",
+			source:      `true 1 2 ifte`,
+			expected:    []StackElement{{Type: Bool, Value: true}},
+			expectedErr: ErrTypeMismatch,
+		},
+		{
+			title:       "Test IFTE with too few elements",
+			source:      `{ 1 } { 2 } ifte`,
+			expected:    []StackElement{{Type: Quotation, Value: []StackElement{{Type: Int, Value: 1}}}, {Type: Quotation, Value: []StackElement{{Type: Int, Value: 2}}}},
+			expectedErr: ErrStackEmpty,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			stack, err := Run(tc.source, false, false)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(stack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, stack)
+			}
+		})
+	}
+}
+
+func TestTokenizeQuotations(t *testing.T) {
+	program, _, err := Tokenize(`{ 1 { 2 } }`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: Quotation, Value: []StackElement{
+		{Type: Int, Value: 1},
+		{Type: Quotation, Value: []StackElement{{Type: Int, Value: 2}}},
+	}}}
+	if !reflect.DeepEqual(program, expected) {
+		t.Errorf("Expected program: %v, but got: %v", expected, program)
+	}
+
+	errorCases := []struct {
+		source      string
+		expectedErr string
+	}{
+		{`{ 1`, "ERROR: { without a matching }"},
+		{`1 }`, "ERROR: } without a matching {"},
+		{`{ def f 1 } end`, "ERROR: } without a matching {"},
+		{`def f { 1 end }`, "ERROR: { without a matching }"},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.source, func(t *testing.T) {
+			_, _, err := Tokenize(tc.source)
+			if err == nil {
+				t.Fatalf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+			if err.Error() != tc.expectedErr {
+				t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestArgmin(t *testing.T) {
+	var testCases = TestCase{
+		// Test ARGMIN_OP of an int list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 1}, {Type: Int, Value: 4}, {Type: Int, Value: 0}, {Type: Int, Value: 5}}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+			},
+			expectedErr: nil,
+			title:       "Test ARGMIN_OP of an int list",
+		},
+		// Test ARGMIN_OP picks the first index on ties
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 1}, {Type: Int, Value: 4}, {Type: Int, Value: 1}, {Type: Int, Value: 5}}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test ARGMIN_OP picks the first index on ties",
+		},
+		// Test ARGMIN_OP of a mixed list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Float, Value: 2.5}, {Type: Int, Value: -1}, {Type: Float, Value: 7.0}}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test ARGMIN_OP of a mixed list",
+		},
+		// Test ARGMIN_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot perform ARGMIN_OP on an empty list"),
+			title:       "Test ARGMIN_OP of an empty list",
+		},
+		// Test ARGMIN_OP of a list with non numeric elements
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test ARGMIN_OP of a list with non numeric elements",
+		},
+		// Test ARGMIN_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test ARGMIN_OP with a non list type",
+		},
+		// Test ARGMIN_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test ARGMIN_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Argmin()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestArgmax(t *testing.T) {
+	var testCases = TestCase{
+		// Test ARGMAX_OP of an int list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 1}, {Type: Int, Value: 4}, {Type: Int, Value: 0}, {Type: Int, Value: 5}}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 4},
+			},
+			expectedErr: nil,
+			title:       "Test ARGMAX_OP of an int list",
+		},
+		// Test ARGMAX_OP picks the first index on ties
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 5}, {Type: Int, Value: 4}, {Type: Int, Value: 5}, {Type: Int, Value: 1}}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test ARGMAX_OP picks the first index on ties",
+		},
+		// Test ARGMAX_OP of a mixed list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Float, Value: 2.5}, {Type: Int, Value: -1}, {Type: Float, Value: 7.0}}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test ARGMAX_OP of a mixed list",
+		},
+		// Test ARGMAX_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot perform ARGMAX_OP on an empty list"),
+			title:       "Test ARGMAX_OP of an empty list",
+		},
+		// Test ARGMAX_OP of a list with non numeric elements
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test ARGMAX_OP of a list with non numeric elements",
+		},
+		// Test ARGMAX_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test ARGMAX_OP with a non list type",
+		},
+		// Test ARGMAX_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test ARGMAX_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Argmax()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestEmit(t *testing.T) {
+	g := NewGorth(false, false)
+
+	g.ExecStack = append(g.ExecStack, StackElement{Type: Int, Value: 65})
+
+	var out bytes.Buffer
+	g.Out = &out
+
+	err := g.Emit()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// no trailing newline, so emits can be chained into a line
+	expectedOutput := "A"
+	actualOutput := out.String()
+	if actualOutput != expectedOutput {
+		t.Errorf("Expected output: %q, but got: %q", expectedOutput, actualOutput)
+	}
+
+	if len(g.ExecStack) != 0 {
+		t.Errorf("Expected an empty stack, but got: %v", g.ExecStack)
+	}
+}
+
+func TestEmitErrors(t *testing.T) {
+	var testCases = TestCase{
+		// Test EMIT_OP with a non integer type
+		{
+			stack: []StackElement{
+				{Type: String, Value: "A"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test EMIT_OP with a non integer type",
+		},
+		// Test EMIT_OP with a negative codepoint
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -1},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: -1 is not a valid codepoint for EMIT_OP"),
+			title:       "Test EMIT_OP with a negative codepoint",
+		},
+		// Test EMIT_OP with a surrogate codepoint
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 55296},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: 55296 is not a valid codepoint for EMIT_OP"),
+			title:       "Test EMIT_OP with a surrogate codepoint",
+		},
+		// Test EMIT_OP with a codepoint past the unicode range
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1114112},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: 1114112 is not a valid codepoint for EMIT_OP"),
+			title:       "Test EMIT_OP with a codepoint past the unicode range",
+		},
+		// Test EMIT_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test EMIT_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Emit()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLTake(t *testing.T) {
+	var testCases = TestCase{
+		// Test LTAKE_OP of the first 2 elements
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test LTAKE_OP of the first 2 elements",
+		},
+		// Test LTAKE_OP with a count past the end of the list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: Int, Value: 10},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+			},
+			expectedErr: nil,
+			title:       "Test LTAKE_OP with a count past the end of the list",
+		},
+		// Test LTAKE_OP with a zero count
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test LTAKE_OP with a zero count",
+		},
+		// Test LTAKE_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test LTAKE_OP of an empty list",
+		},
+		// Test LTAKE_OP with a negative count
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: Int, Value: -1},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+			},
+			expectedErr: errors.New("ERROR: cannot perform LTAKE_OP with a negative count"),
+			title:       "Test LTAKE_OP with a negative count",
+		},
+		// Test LTAKE_OP with a non integer count
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: String, Value: "2"},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LTAKE_OP with a non integer count",
+		},
+		// Test LTAKE_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LTAKE_OP with a non list type",
+		},
+		// Test LTAKE_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test LTAKE_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.LTake()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLDrop(t *testing.T) {
+	var testCases = TestCase{
+		// Test LDROP_OP of the first 2 elements
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+			},
+			expectedErr: nil,
+			title:       "Test LDROP_OP of the first 2 elements",
+		},
+		// Test LDROP_OP with a count past the end of the list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: Int, Value: 10},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test LDROP_OP with a count past the end of the list",
+		},
+		// Test LDROP_OP with a zero count
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+			},
+			expectedErr: nil,
+			title:       "Test LDROP_OP with a zero count",
+		},
+		// Test LDROP_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test LDROP_OP of an empty list",
+		},
+		// Test LDROP_OP with a negative count
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: Int, Value: -1},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+			},
+			expectedErr: errors.New("ERROR: cannot perform LDROP_OP with a negative count"),
+			title:       "Test LDROP_OP with a negative count",
+		},
+		// Test LDROP_OP with a non integer count
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: String, Value: "2"},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LDROP_OP with a non integer count",
+		},
+		// Test LDROP_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LDROP_OP with a non list type",
+		},
+		// Test LDROP_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test LDROP_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.LDrop()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	var testCases = TestCase{
+		// Test DURATION_OP of a multi unit duration
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 3661},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "1h1m1s"},
+			},
+			expectedErr: nil,
+			title:       "Test DURATION_OP of a multi unit duration",
+		},
+		// Test DURATION_OP of minutes and seconds
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 90},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "1m30s"},
+			},
+			expectedErr: nil,
+			title:       "Test DURATION_OP of minutes and seconds",
+		},
+		// Test DURATION_OP of zero
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "0s"},
+			},
+			expectedErr: nil,
+			title:       "Test DURATION_OP of zero",
+		},
+		// Test DURATION_OP of a negative duration
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -3661},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "-1h1m1s"},
+			},
+			expectedErr: nil,
+			title:       "Test DURATION_OP of a negative duration",
+		},
+		// Test DURATION_OP with a non integer type
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.5},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test DURATION_OP with a non integer type",
+		},
+		// Test DURATION_OP with an out of range value
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 10000000000},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: 10000000000 seconds is out of range for DURATION_OP"),
+			title:       "Test DURATION_OP with an out of range value",
+		},
+		// Test DURATION_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test DURATION_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Duration()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestDateFmt(t *testing.T) {
+	now := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+
+	var testCases = []struct {
+		layout      StackElement
+		expected    []StackElement
+		expectedErr error
+		title       string
+	}{
+		{
+			layout:   StackElement{Type: String, Value: "2006-01-02"},
+			expected: []StackElement{{Type: String, Value: "2024-03-05"}},
+			title:    "Test DATEFMT_OP of a date layout",
+		},
+		{
+			layout:   StackElement{Type: String, Value: "15:04:05"},
+			expected: []StackElement{{Type: String, Value: "14:07:09"}},
+			title:    "Test DATEFMT_OP of a time layout",
+		},
+		{
+			layout:   StackElement{Type: String, Value: "no layout"},
+			expected: []StackElement{{Type: String, Value: "no layout"}},
+			title:    "Test DATEFMT_OP passes text without layout elements through",
+		},
+		{
+			layout:      StackElement{Type: Int, Value: 2006},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test DATEFMT_OP with a non string layout",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.Now = func() time.Time { return now }
+			g.ExecStack = []StackElement{tc.layout}
+
+			err := g.DateFmt()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestAbs(t *testing.T) {
+	var testCases = TestCase{
+		// Test ABS_OP of a negative integer
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -5},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test ABS_OP of a negative integer",
+		},
+		// Test ABS_OP of a positive integer
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test ABS_OP of a positive integer",
+		},
+		// Test ABS_OP of a negative float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: -3.5},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 3.5},
+			},
+			expectedErr: nil,
+			title:       "Test ABS_OP of a negative float",
+		},
+		// Test ABS_OP of a variable holding a negative value
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: -7},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 7},
+			},
+			expectedErr: nil,
+			title:       "Test ABS_OP of a variable holding a negative value",
+		},
+		// Test ABS_OP of an undeclared variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "y"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrUndeclaredVariable,
+			title:       "Test ABS_OP of an undeclared variable",
+		},
+		// Test ABS_OP of the smallest integer
+		{
+			stack: []StackElement{
+				{Type: Int, Value: math.MinInt},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: integer overflow in ABS_OP"),
+			title:       "Test ABS_OP of the smallest integer",
+		},
+		// Test ABS_OP of a string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "-5"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test ABS_OP of a string",
+		},
+		// Test ABS_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test ABS_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Abs()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestSleep(t *testing.T) {
+	var testCases = []struct {
+		stack       []StackElement
+		allowSleep  bool
+		slept       []time.Duration
+		expectedErr error
+		title       string
+	}{
+		{
+			stack:      []StackElement{{Type: Int, Value: 500}},
+			allowSleep: true,
+			slept:      []time.Duration{500 * time.Millisecond},
+			title:      "Test SLEEP_OP sleeps for the given milliseconds",
+		},
+		{
+			stack:      []StackElement{{Type: Int, Value: 0}},
+			allowSleep: true,
+			slept:      []time.Duration{0},
+			title:      "Test SLEEP_OP of zero milliseconds",
+		},
+		{
+			stack:       []StackElement{{Type: Int, Value: 500}},
+			allowSleep:  false,
+			expectedErr: errors.New("ERROR: SLEEP_OP is disabled"),
+			title:       "Test SLEEP_OP when sleeping is disabled",
+		},
+		{
+			stack:       []StackElement{{Type: Int, Value: -1}},
+			allowSleep:  true,
+			expectedErr: errors.New("ERROR: cannot perform SLEEP_OP with a negative duration"),
+			title:       "Test SLEEP_OP with a negative duration",
+		},
+		{
+			stack:       []StackElement{{Type: Float, Value: 1.5}},
+			allowSleep:  true,
+			expectedErr: ErrTypeMismatch,
+			title:       "Test SLEEP_OP with a non integer type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			var slept []time.Duration
+			g := NewGorth(false, false)
+			g.AllowSleep = tc.allowSleep
+			g.Sleep = func(d time.Duration) { slept = append(slept, d) }
+			g.ExecStack = tc.stack
+
+			err := g.Pause()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(slept, tc.slept) {
+				t.Errorf("Expected sleeps: %v, but got: %v", tc.slept, slept)
+			}
+		})
+	}
+}
+
+func TestSqrt(t *testing.T) {
+	var testCases = TestCase{
+		// Test SQRT_OP of an integer
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 9},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 3.0},
+			},
+			expectedErr: nil,
+			title:       "Test SQRT_OP of an integer",
+		},
+		// Test SQRT_OP of a float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 2.25},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 1.5},
+			},
+			expectedErr: nil,
+			title:       "Test SQRT_OP of a float",
+		},
+		// Test SQRT_OP of zero
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 0.0},
+			},
+			expectedErr: nil,
+			title:       "Test SQRT_OP of zero",
+		},
+		// Test SQRT_OP of a variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 16},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 4.0},
+			},
+			expectedErr: nil,
+			title:       "Test SQRT_OP of a variable",
+		},
+		// Test SQRT_OP of a negative number
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -1},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot perform SQRT_OP on a negative number"),
+			title:       "Test SQRT_OP of a negative number",
+		},
+		// Test SQRT_OP of a string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "9"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test SQRT_OP of a string",
+		},
+		// Test SQRT_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test SQRT_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Sqrt()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestDigitSum(t *testing.T) {
+	var testCases = TestCase{
+		// Test DIGITSUM_OP of a positive number
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 12345},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 15},
+			},
+			expectedErr: nil,
+			title:       "Test DIGITSUM_OP of a positive number",
+		},
+		// Test DIGITSUM_OP of a negative number
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -907},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 16},
+			},
+			expectedErr: nil,
+			title:       "Test DIGITSUM_OP of a negative number",
+		},
+		// Test DIGITSUM_OP of zero
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test DIGITSUM_OP of zero",
+		},
+		// Test DIGITSUM_OP of the smallest integer
+		{
+			stack: []StackElement{
+				{Type: Int, Value: math.MinInt},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 89},
+			},
+			expectedErr: nil,
+			title:       "Test DIGITSUM_OP of the smallest integer",
+		},
+		// Test DIGITSUM_OP of a float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.5},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test DIGITSUM_OP of a float",
+		},
+		// Test DIGITSUM_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test DIGITSUM_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.DigitSum()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestBitAnd(t *testing.T) {
+	var testCases = TestCase{
+		// Test BIT_AND_OP of two integers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test BIT_AND_OP of two integers",
+		},
+		// Test BIT_AND_OP with a negative integer
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -1},
+				{Type: Int, Value: 12},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 12},
+			},
+			expectedErr: nil,
+			title:       "Test BIT_AND_OP with a negative integer",
+		},
+		// Test BIT_AND_OP of a variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 3},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 6},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test BIT_AND_OP of a variable",
+		},
+		// Test BIT_AND_OP with a non integer type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+				{Type: Float, Value: 3.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test BIT_AND_OP with a non integer type",
+		},
+		// Test BIT_AND_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test BIT_AND_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.BitAnd()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestBitOr(t *testing.T) {
+	var testCases = TestCase{
+		// Test BIT_OR_OP of two integers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 7},
+			},
+			expectedErr: nil,
+			title:       "Test BIT_OR_OP of two integers",
+		},
+		// Test BIT_OR_OP with zero
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test BIT_OR_OP with zero",
+		},
+		// Test BIT_OR_OP with a non integer type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+				{Type: Float, Value: 3.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test BIT_OR_OP with a non integer type",
+		},
+		// Test BIT_OR_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test BIT_OR_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.BitOr()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestBitXor(t *testing.T) {
+	var testCases = TestCase{
+		// Test BIT_XOR_OP of two integers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test BIT_XOR_OP of two integers",
+		},
+		// Test BIT_XOR_OP of equal integers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 9},
+				{Type: Int, Value: 9},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test BIT_XOR_OP of equal integers",
+		},
+		// Test BIT_XOR_OP with a non integer type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+				{Type: Float, Value: 3.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test BIT_XOR_OP with a non integer type",
+		},
+		// Test BIT_XOR_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test BIT_XOR_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.BitXor()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestShiftLeft(t *testing.T) {
+	var testCases = TestCase{
+		// Test SHIFT_LEFT_OP of two integers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 4},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 16},
+			},
+			expectedErr: nil,
+			title:       "Test SHIFT_LEFT_OP of two integers",
+		},
+		// Test SHIFT_LEFT_OP by zero
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 7},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 7},
+			},
+			expectedErr: nil,
+			title:       "Test SHIFT_LEFT_OP by zero",
+		},
+		// Test SHIFT_LEFT_OP with a negative shift count
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: -1},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot perform SHIFT_LEFT_OP with a negative shift count"),
+			title:       "Test SHIFT_LEFT_OP with a negative shift count",
+		},
+		// Test SHIFT_LEFT_OP with a non integer type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+				{Type: Float, Value: 3.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test SHIFT_LEFT_OP with a non integer type",
+		},
+		// Test SHIFT_LEFT_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test SHIFT_LEFT_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.ShiftLeft()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestShiftRight(t *testing.T) {
+	var testCases = TestCase{
+		// Test SHIFT_RIGHT_OP of two integers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 16},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 4},
+			},
+			expectedErr: nil,
+			title:       "Test SHIFT_RIGHT_OP of two integers",
+		},
+		// Test SHIFT_RIGHT_OP keeps the sign
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -16},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: -4},
+			},
+			expectedErr: nil,
+			title:       "Test SHIFT_RIGHT_OP keeps the sign",
+		},
+		// Test SHIFT_RIGHT_OP with a negative shift count
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: -1},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot perform SHIFT_RIGHT_OP with a negative shift count"),
+			title:       "Test SHIFT_RIGHT_OP with a negative shift count",
+		},
+		// Test SHIFT_RIGHT_OP with a non integer type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+				{Type: Float, Value: 3.0},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test SHIFT_RIGHT_OP with a non integer type",
+		},
+		// Test SHIFT_RIGHT_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test SHIFT_RIGHT_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.ShiftRight()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestBitwiseProgram(t *testing.T) {
+	// the single character operators must not be confused with && and ||
+	stack, err := Run(`6 3 & 1 4 << 5 3 | 6 3 xor true false || true true && -16 2 >>`, false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{
+		{Type: Int, Value: 2},
+		{Type: Int, Value: 16},
+		{Type: Int, Value: 7},
+		{Type: Int, Value: 5},
+		{Type: Bool, Value: true},
+		{Type: Bool, Value: true},
+		{Type: Int, Value: -4},
+	}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, stack)
+	}
+}
+
+func TestRevDigits(t *testing.T) {
+	var testCases = TestCase{
+		// Test REVDIGITS_OP of a positive number
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 12345},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 54321},
+			},
+			expectedErr: nil,
+			title:       "Test REVDIGITS_OP of a positive number",
+		},
+		// Test REVDIGITS_OP of a negative number
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -12345},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: -54321},
+			},
+			expectedErr: nil,
+			title:       "Test REVDIGITS_OP of a negative number",
+		},
+		// Test REVDIGITS_OP drops trailing zeros
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1200},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 21},
+			},
+			expectedErr: nil,
+			title:       "Test REVDIGITS_OP drops trailing zeros",
+		},
+		// Test REVDIGITS_OP of zero
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test REVDIGITS_OP of zero",
+		},
+		// Test REVDIGITS_OP of the largest reversible number
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 7085774586302733229},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 9223372036854775807},
+			},
+			expectedErr: nil,
+			title:       "Test REVDIGITS_OP of the largest reversible number",
+		},
+		// Test REVDIGITS_OP of a number whose reversal overflows
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1999999999999999999},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: integer overflow in REVDIGITS_OP"),
+			title:       "Test REVDIGITS_OP of a number whose reversal overflows",
+		},
+		// Test REVDIGITS_OP of a negative number whose reversal overflows
+		{
+			stack: []StackElement{
+				{Type: Int, Value: -1999999999999999999},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: integer overflow in REVDIGITS_OP"),
+			title:       "Test REVDIGITS_OP of a negative number whose reversal overflows",
+		},
+		// Test REVDIGITS_OP of a string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "123"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test REVDIGITS_OP of a string",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.RevDigits()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	var testCases = TestCase{
+		// Test APPLY_OP of +
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 4},
+				{Type: String, Value: "+"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 7},
+			},
+			expectedErr: nil,
+			title:       "Test APPLY_OP of +",
+		},
+		// Test APPLY_OP of dup
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 3},
+				{Type: String, Value: "dup"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 3},
+			},
+			expectedErr: nil,
+			title:       "Test APPLY_OP of dup",
+		},
+		// Test APPLY_OP of an operator that errors
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: Int, Value: 1},
+				{Type: String, Value: "+"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test APPLY_OP of an operator that errors",
+		},
+		// Test APPLY_OP of an unknown operator
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 3},
+				{Type: String, Value: "nope"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+			},
+			expectedErr: errors.New("ERROR: unknown operator nope for APPLY_OP"),
+			title:       "Test APPLY_OP of an unknown operator",
+		},
+		// Test APPLY_OP of a non string
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 4},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test APPLY_OP of a non string",
+		},
+		// Test APPLY_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test APPLY_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Apply()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestFloatEqualityEpsilon(t *testing.T) {
+	stack, err := Run(`0.1 0.2 + 0.3 ==`, false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: Bool, Value: true}}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, stack)
+	}
+
+	// an epsilon of 0 compares floats exactly
+	g := NewGorth(false, false)
+	g.Epsilon = 0
+	err = g.Run(`0.1 0.2 + 0.3 ==`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected = []StackElement{{Type: Bool, Value: false}}
+	if !reflect.DeepEqual(g.ExecStack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+	}
+}
+
+func TestLcp(t *testing.T) {
+	var testCases = TestCase{
+		// Test LCP_OP of strings with a shared prefix
+		{
+			stack: []StackElement{
+				{Type: String, Value: "foobar"},
+				{Type: String, Value: "foobaz"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test LCP_OP of strings with a shared prefix",
+		},
+		// Test LCP_OP of identical strings
+		{
+			stack: []StackElement{
+				{Type: String, Value: "gorth"},
+				{Type: String, Value: "gorth"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test LCP_OP of identical strings",
+		},
+		// Test LCP_OP of strings without a common prefix
+		{
+			stack: []StackElement{
+				{Type: String, Value: "abc"},
+				{Type: String, Value: "xyz"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test LCP_OP of strings without a common prefix",
+		},
+		// Test LCP_OP when one string is a prefix of the other
+		{
+			stack: []StackElement{
+				{Type: String, Value: "go"},
+				{Type: String, Value: "gorth"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test LCP_OP when one string is a prefix of the other",
+		},
+		// Test LCP_OP counts runes
+		{
+			stack: []StackElement{
+				{Type: String, Value: "héllo"},
+				{Type: String, Value: "hélp"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+			},
+			expectedErr: nil,
+			title:       "Test LCP_OP counts runes",
+		},
+		// Test LCP_OP of a variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: String, Value: "foo"},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: String, Value: "food"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+			},
+			expectedErr: nil,
+			title:       "Test LCP_OP of a variable",
+		},
+		// Test LCP_OP with a non string type
+		{
+			stack: []StackElement{
+				{Type: String, Value: "1"},
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LCP_OP with a non string type",
+		},
+		// Test LCP_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test LCP_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Lcp()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestNotEqual(t *testing.T) {
+	var testCases = TestCase{
+		// Test NOT_EQUAL_OP of different integers
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test NOT_EQUAL_OP of different integers",
+		},
+		// Test NOT_EQUAL_OP of equal strings
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: String, Value: "a"},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test NOT_EQUAL_OP of equal strings",
+		},
+		// Test NOT_EQUAL_OP of variables
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 3},
+			},
+			variableMap: map[string]Variable{
+				"x": {Name: "x", Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test NOT_EQUAL_OP of variables",
+		},
+		// Test NOT_EQUAL_OP of an undeclared variable
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Identifier, Value: "y"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrUndeclaredVariable,
+			title:       "Test NOT_EQUAL_OP of an undeclared variable",
+		},
+		// Test NOT_EQUAL_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test NOT_EQUAL_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.NotEqual()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestNotEqualUndeclaredProgram(t *testing.T) {
+	// the error from != used to be dropped, running the rest of the program on a bad stack
+	program := []StackElement{
+		{Type: Int, Value: 1},
+		{Type: Identifier, Value: "missing"},
+		{Type: Operator, Value: NOT_EQUAL_OP},
+		{Type: Int, Value: 2},
+	}
+
+	g := NewGorth(false, false)
+	err := g.ExecuteProgram(program)
+	if !errors.Is(err, ErrUndeclaredVariable) {
+		t.Fatalf("Expected ErrUndeclaredVariable, but got: %v", err)
+	}
+
+	if len(g.ExecStack) != 0 {
+		t.Errorf("Expected execution to stop at !=, but got stack: %v", g.ExecStack)
+	}
+}
+
+func TestMaxSteps(t *testing.T) {
+	testCases := []struct {
+		title       string
+		source      string
+		maxSteps    int
+		expectedErr error
+	}{
+		{
+			title:       "Test a long program past the step limit",
+			source:      strings.Repeat("1 drop ", 50),
+			maxSteps:    10,
+			expectedErr: errors.New("ERROR: step limit exceeded"),
+		},
+		{
+			title:       "Test a loop past the step limit",
+			source:      `0 while true do 1 + end`,
+			maxSteps:    100,
+			expectedErr: errors.New("ERROR: step limit exceeded"),
+		},
+		{
+			title:       "Test recursion past the step limit",
+			source:      `def forever forever end forever`,
+			maxSteps:    100,
+			expectedErr: errors.New("ERROR: step limit exceeded"),
+		},
+		{
+			title:    "Test a program within the step limit",
+			source:   strings.Repeat("1 drop ", 5),
+			maxSteps: 10,
+		},
+		{
+			title:    "Test a step limit of 0 is unlimited",
+			source:   strings.Repeat("1 drop ", 50),
+			maxSteps: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.MaxSteps = tc.maxSteps
+
+			err := g.Run(tc.source)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	var testCases = TestCase{
+		// Test INTERLEAVE_OP of equal length lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 4}, {Type: Int, Value: 5}, {Type: Int, Value: 6}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 4}, {Type: Int, Value: 2}, {Type: Int, Value: 5}, {Type: Int, Value: 3}, {Type: Int, Value: 6}}},
+			},
+			expectedErr: nil,
+			title:       "Test INTERLEAVE_OP of equal length lists",
+		},
+		// Test INTERLEAVE_OP with a longer first list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 5}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 5}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+			},
+			expectedErr: nil,
+			title:       "Test INTERLEAVE_OP with a longer first list",
+		},
+		// Test INTERLEAVE_OP with a longer second list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}}},
+				{Type: List, Value: []StackElement{{Type: String, Value: "b"}, {Type: String, Value: "c"}, {Type: String, Value: "d"}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: String, Value: "b"}, {Type: String, Value: "c"}, {Type: String, Value: "d"}}},
+			},
+			expectedErr: nil,
+			title:       "Test INTERLEAVE_OP with a longer second list",
+		},
+		// Test INTERLEAVE_OP with an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test INTERLEAVE_OP with an empty list",
+		},
+		// Test INTERLEAVE_OP of empty lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test INTERLEAVE_OP of empty lists",
+		},
+		// Test INTERLEAVE_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test INTERLEAVE_OP with a non list type",
+		},
+		// Test INTERLEAVE_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test INTERLEAVE_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Interleave()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestHamming(t *testing.T) {
+	var testCases = TestCase{
+		// Test HAMMING_OP of a typical pair
+		{
+			stack: []StackElement{
+				{Type: String, Value: "karolin"},
+				{Type: String, Value: "kathrin"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+			},
+			expectedErr: nil,
+			title:       "Test HAMMING_OP of a typical pair",
+		},
+		// Test HAMMING_OP of identical strings
+		{
+			stack: []StackElement{
+				{Type: String, Value: "gorth"},
+				{Type: String, Value: "gorth"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test HAMMING_OP of identical strings",
+		},
+		// Test HAMMING_OP of empty strings
+		{
+			stack: []StackElement{
+				{Type: String, Value: ""},
+				{Type: String, Value: ""},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test HAMMING_OP of empty strings",
+		},
+		// Test HAMMING_OP counts runes
+		{
+			stack: []StackElement{
+				{Type: String, Value: "héllo"},
+				{Type: String, Value: "hallo"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: nil,
+			title:       "Test HAMMING_OP counts runes",
+		},
+		// Test HAMMING_OP with a length mismatch
+		{
+			stack: []StackElement{
+				{Type: String, Value: "abc"},
+				{Type: String, Value: "ab"},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: HAMMING_OP expects strings of the same length, but got 3 and 2"),
+			title:       "Test HAMMING_OP with a length mismatch",
+		},
+		// Test HAMMING_OP with a non string type
+		{
+			stack: []StackElement{
+				{Type: String, Value: "1"},
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test HAMMING_OP with a non string type",
+		},
+		// Test HAMMING_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test HAMMING_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Hamming()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestStackElementString(t *testing.T) {
+	g := NewGorth(false, false)
+	g.ExecStack = []StackElement{
+		{Type: Int, Value: 15},
+		{Type: Float, Value: 2.5},
+		{Type: String, Value: "hi"},
+		{Type: Bool, Value: true},
+		{Type: Identifier, Value: "x"},
+		{Type: Operator, Value: ADD_OP},
+		{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+	}
+
+	var out bytes.Buffer
+	g.Out = &out
+	g.PrintStack()
+
+	expectedOutput := `Program stack: [int(15) float(2.5) string("hi") bool(true) identifier(x) operator(+) list([int(1) string("a")])]` + "\n"
+	actualOutput := out.String()
+	if actualOutput != expectedOutput {
+		t.Errorf("Expected output: %q, but got: %q", expectedOutput, actualOutput)
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	var testCases = TestCase{
+		// Test TRANSPOSE_OP of a 2x2 list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 4}}}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 3}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 2}, {Type: Int, Value: 4}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test TRANSPOSE_OP of a 2x2 list",
+		},
+		// Test TRANSPOSE_OP of a 2x3 list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 4}, {Type: Int, Value: 5}, {Type: Int, Value: 6}}}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 4}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 2}, {Type: Int, Value: 5}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 6}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test TRANSPOSE_OP of a 2x3 list",
+		},
+		// Test TRANSPOSE_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test TRANSPOSE_OP of an empty list",
+		},
+		// Test TRANSPOSE_OP of a list of empty lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{}}, {Type: List, Value: []StackElement{}}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test TRANSPOSE_OP of a list of empty lists",
+		},
+		// Test TRANSPOSE_OP of a ragged list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 3}}}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: TRANSPOSE_OP expects lists of the same length, but row 1 has 1 elements instead of 2"),
+			title:       "Test TRANSPOSE_OP of a ragged list",
+		},
+		// Test TRANSPOSE_OP of a list of non lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test TRANSPOSE_OP of a list of non lists",
+		},
+		// Test TRANSPOSE_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test TRANSPOSE_OP with a non list type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Transpose()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLClamp(t *testing.T) {
+	var testCases = TestCase{
+		// Test LCLAMP_OP below, within and above the range
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: -1}, {Type: Int, Value: 5}, {Type: Int, Value: 11}}},
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 10},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 0}, {Type: Int, Value: 5}, {Type: Int, Value: 10}}},
+			},
+			expectedErr: nil,
+			title:       "Test LCLAMP_OP below, within and above the range",
+		},
+		// Test LCLAMP_OP keeps floats
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Float, Value: -1.5}, {Type: Float, Value: 2.5}, {Type: Float, Value: 12.0}}},
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 10},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Float, Value: 0.0}, {Type: Float, Value: 2.5}, {Type: Float, Value: 10.0}}},
+			},
+			expectedErr: nil,
+			title:       "Test LCLAMP_OP keeps floats",
+		},
+		// Test LCLAMP_OP with float bounds
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: -1}, {Type: Int, Value: 1}, {Type: Int, Value: 3}}},
+				{Type: Float, Value: 0.5},
+				{Type: Float, Value: 2.5},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test LCLAMP_OP with float bounds",
+		},
+		// Test LCLAMP_OP keeps ints clamped to a float low bound inside the range
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 9}}},
+				{Type: Float, Value: 2.5},
+				{Type: Float, Value: 7.5},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 7}}},
+			},
+			expectedErr: nil,
+			title:       "Test LCLAMP_OP of an int list with float bounds",
+		},
+		// Test LCLAMP_OP converts int bounds for floats
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Float, Value: 0.5}, {Type: Int, Value: 4}}},
+				{Type: Int, Value: 1},
+				{Type: Float, Value: 3.5},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Float, Value: 1.0}, {Type: Int, Value: 3}}},
+			},
+			expectedErr: nil,
+			title:       "Test LCLAMP_OP of a mixed list with mixed bounds",
+		},
+		// Test LCLAMP_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 10},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test LCLAMP_OP of an empty list",
+		},
+		// Test LCLAMP_OP with inverted bounds
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: 10},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: errors.New("ERROR: LCLAMP_OP expects the low bound to be at most the high bound"),
+			title:       "Test LCLAMP_OP with inverted bounds",
+		},
+		// Test LCLAMP_OP with non numeric elements
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 10},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LCLAMP_OP with non numeric elements",
+		},
+		// Test LCLAMP_OP with non numeric bounds
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: String, Value: "0"},
+				{Type: Int, Value: 10},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LCLAMP_OP with non numeric bounds",
+		},
+		// Test LCLAMP_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 10},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LCLAMP_OP with a non list type",
+		},
+		// Test LCLAMP_OP with too few elements
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 10},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 10},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test LCLAMP_OP with too few elements",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.LClamp()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestMarshalState(t *testing.T) {
+	g := NewGorth(false, false)
+	g.ExecStack = []StackElement{
+		{Type: Int, Value: 15},
+		{Type: Float, Value: 2.0},
+		{Type: String, Value: "hi"},
+		{Type: Bool, Value: true},
+		{Type: Identifier, Value: "x"},
+		{Type: Operator, Value: ADD_OP},
+		{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: List, Value: []StackElement{{Type: Float, Value: 0.5}}}}},
+		{Type: Quotation, Value: []StackElement{{Type: Int, Value: 2}, {Type: Operator, Value: MUL_OP}}},
+		{Type: List, Value: []StackElement{}},
+		{Type: Map, Value: map[string]StackElement{"a": {Type: Int, Value: 1}, "b": {Type: List, Value: []StackElement{{Type: String, Value: "c"}}}}},
+	}
+	g.VariableMap = map[string]Variable{
+		"x":  {Name: "x", Type: Int, Value: 7},
+		"pi": {Name: "pi", Type: Float, Value: 3.14, Const: true},
+		"s":  {Name: "s", Type: String, Value: "gorth"},
+	}
+
+	data, err := g.MarshalState()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	loaded := NewGorth(false, false)
+	err = loaded.LoadState(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded.ExecStack, g.ExecStack) {
+		t.Errorf("Expected stack: %v, but got: %v", g.ExecStack, loaded.ExecStack)
+	}
+
+	if !reflect.DeepEqual(loaded.VariableMap, g.VariableMap) {
+		t.Errorf("Expected variables: %v, but got: %v", g.VariableMap, loaded.VariableMap)
+	}
+}
+
+func TestLoadStateErrors(t *testing.T) {
+	testCases := []struct {
+		title string
+		data  string
+	}{
+		{title: "Test LoadState with invalid JSON", data: `{"stack": [`},
+		{title: "Test LoadState with an unknown type", data: `{"stack": [{"type": "complex", "value": 1}]}`},
+		{title: "Test LoadState with an unknown operator", data: `{"stack": [{"type": "operator", "value": "nope"}]}`},
+		{title: "Test LoadState with a value of the wrong type", data: `{"stack": [{"type": "int", "value": "1"}]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = []StackElement{{Type: Int, Value: 1}}
+
+			err := g.LoadState([]byte(tc.data))
+			if err == nil {
+				t.Errorf("Expected an error, but got nil")
+			}
+
+			// a failed load leaves the state alone
+			expected := []StackElement{{Type: Int, Value: 1}}
+			if !reflect.DeepEqual(g.ExecStack, expected) {
+				t.Errorf("Expected stack: %v, but got: %v", expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestSaveAndLoadStack(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "stack.json")
+
+	g := NewGorth(false, false)
+	stack := []StackElement{
+		{Type: Int, Value: 1},
+		{Type: Float, Value: 2.0},
+		{Type: String, Value: "three"},
+		{Type: List, Value: []StackElement{{Type: Bool, Value: true}}},
+	}
+	g.ExecStack = append(append([]StackElement{}, stack...), StackElement{Type: String, Value: filename})
+
+	err := g.SaveStack()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(g.ExecStack, stack) {
+		t.Errorf("Expected stack: %v, but got: %v", stack, g.ExecStack)
+	}
+
+	// clear the stack, then load it back over some other value
+	g.ExecStack = []StackElement{{Type: Int, Value: 99}, {Type: String, Value: filename}}
+
+	err = g.LoadStack()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(g.ExecStack, stack) {
+		t.Errorf("Expected stack: %v, but got: %v", stack, g.ExecStack)
+	}
+}
+
+func TestSaveAndLoadStackProgram(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "stack.json")
+
+	_, err := Run(fmt.Sprintf(`1 2 + "x" %q savestack`, filename), false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stack, err := Run(fmt.Sprintf(`%q loadstack swap`, filename), false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: String, Value: "x"}, {Type: Int, Value: 3}}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, stack)
+	}
+}
+
+func TestSaveAndLoadStackWithVariables(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "stack.json")
+
+	_, err := Run(fmt.Sprintf(`/x 5 def /name "gorth" def %q savestack`, filename), false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// the loading run has no variables, so the saved stack has to hold their values
+	stack, err := Run(fmt.Sprintf(`%q loadstack swap 1 +`, filename), false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: String, Value: "gorth"}, {Type: Int, Value: 6}}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, stack)
+	}
+}
+
+func TestLoadStackErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	err := os.WriteFile(invalid, []byte("not json"), 0644)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		title string
+		stack []StackElement
+		call  func(g *Gorth) error
+	}{
+		{
+			title: "Test LOAD_STACK_OP with a missing file",
+			stack: []StackElement{{Type: String, Value: filepath.Join(dir, "missing.json")}},
+			call:  (*Gorth).LoadStack,
+		},
+		{
+			title: "Test LOAD_STACK_OP with an invalid file",
+			stack: []StackElement{{Type: String, Value: invalid}},
+			call:  (*Gorth).LoadStack,
+		},
+		{
+			title: "Test LOAD_STACK_OP with a non string filename",
+			stack: []StackElement{{Type: Int, Value: 1}},
+			call:  (*Gorth).LoadStack,
+		},
+		{
+			title: "Test SAVE_STACK_OP with a non string filename",
+			stack: []StackElement{{Type: Int, Value: 1}},
+			call:  (*Gorth).SaveStack,
+		},
+		{
+			title: "Test SAVE_STACK_OP into a missing directory",
+			stack: []StackElement{{Type: String, Value: filepath.Join(dir, "missing", "stack.json")}},
+			call:  (*Gorth).SaveStack,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+
+			err := tc.call(g)
+			if err == nil {
+				t.Errorf("Expected an error, but got nil")
+			}
+		})
+	}
+}
+
+func TestLScale(t *testing.T) {
+	var testCases = TestCase{
+		// Test LSCALE_OP of an int list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}, {Type: Int, Value: 4}, {Type: Int, Value: 6}}},
+			},
+			expectedErr: nil,
+			title:       "Test LSCALE_OP of an int list",
+		},
+		// Test LSCALE_OP by a float factor
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+				{Type: Float, Value: 0.5},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Float, Value: 0.5}, {Type: Float, Value: 1.0}}},
+			},
+			expectedErr: nil,
+			title:       "Test LSCALE_OP by a float factor",
+		},
+		// Test LSCALE_OP of a mixed list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Float, Value: 1.5}}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}, {Type: Float, Value: 3.0}}},
+			},
+			expectedErr: nil,
+			title:       "Test LSCALE_OP of a mixed list",
+		},
+		// Test LSCALE_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test LSCALE_OP of an empty list",
+		},
+		// Test LSCALE_OP with a non numeric element
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+				{Type: Int, Value: 2},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LSCALE_OP with a non numeric element",
+		},
+		// Test LSCALE_OP with a non numeric factor
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: String, Value: "2"},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LSCALE_OP with a non numeric factor",
+		},
+		// Test LSCALE_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LSCALE_OP with a non list type",
+		},
+		// Test LSCALE_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test LSCALE_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.LScale()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestCumSum(t *testing.T) {
+	var testCases = TestCase{
+		// Test CUMSUM_OP of an int list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 3}, {Type: Int, Value: 6}}},
+			},
+			expectedErr: nil,
+			title:       "Test CUMSUM_OP of an int list",
+		},
+		// Test CUMSUM_OP of a mixed list, the totals become floats from the first float
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Float, Value: 0.5}, {Type: Int, Value: 2}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Float, Value: 1.5}, {Type: Float, Value: 3.5}}},
+			},
+			expectedErr: nil,
+			title:       "Test CUMSUM_OP of a mixed list",
+		},
+		// Test CUMSUM_OP of a float list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Float, Value: 0.25}, {Type: Float, Value: 0.25}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Float, Value: 0.25}, {Type: Float, Value: 0.5}}},
+			},
+			expectedErr: nil,
+			title:       "Test CUMSUM_OP of a float list",
+		},
+		// Test CUMSUM_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test CUMSUM_OP of an empty list",
+		},
+		// Test CUMSUM_OP with a non numeric element
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test CUMSUM_OP with a non numeric element",
+		},
+		// Test CUMSUM_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test CUMSUM_OP with a non list type",
+		},
+		// Test CUMSUM_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test CUMSUM_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.CumSum()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLen(t *testing.T) {
+	var testCases = TestCase{
+		// Test LEN_OP of a string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test LEN_OP of a string",
+		},
+		// Test LEN_OP counts runes
+		{
+			stack: []StackElement{
+				{Type: String, Value: "héllo"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 5},
+			},
+			expectedErr: nil,
+			title:       "Test LEN_OP counts runes",
+		},
+		// Test LEN_OP of an empty string
+		{
+			stack: []StackElement{
+				{Type: String, Value: ""},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test LEN_OP of an empty string",
+		},
+		// Test LEN_OP of a string variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "name"},
+			},
+			variableMap: map[string]Variable{
+				"name": {Name: "name", Type: String, Value: "Joshua"},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 6},
+			},
+			expectedErr: nil,
+			title:       "Test LEN_OP of a string variable",
+		},
+		// Test LEN_OP of a list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+			},
+			expectedErr: nil,
+			title:       "Test LEN_OP of a list",
+		},
+		// Test LEN_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 0},
+			},
+			expectedErr: nil,
+			title:       "Test LEN_OP of an empty list",
+		},
+		// Test LEN_OP of an int
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LEN_OP of an int",
+		},
+		// Test LEN_OP of a float
+		{
+			stack: []StackElement{
+				{Type: Float, Value: 1.5},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LEN_OP of a float",
+		},
+		// Test LEN_OP of a bool
+		{
+			stack: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LEN_OP of a bool",
+		},
+		// Test LEN_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test LEN_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Len()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	var testCases = TestCase{
+		// Test INDEX_OP of element 1
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test INDEX_OP of element 1",
+		},
+		// Test INDEX_OP of a nested list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: List, Value: []StackElement{{Type: Int, Value: 2}}}}},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: List, Value: []StackElement{{Type: Int, Value: 2}}}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test INDEX_OP of a nested list",
+		},
+		// Test INDEX_OP past the end of the list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expectedErr: errors.New("ERROR: INDEX_OP index 3 is out of range for a list of 3 elements"),
+			title:       "Test INDEX_OP past the end of the list",
+		},
+		// Test INDEX_OP with a negative index
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+				{Type: Int, Value: -1},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expectedErr: errors.New("ERROR: INDEX_OP index -1 is out of range for a list of 3 elements"),
+			title:       "Test INDEX_OP with a negative index",
+		},
+		// Test INDEX_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: errors.New("ERROR: cannot perform INDEX_OP on an empty list"),
+			title:       "Test INDEX_OP of an empty list",
+		},
+		// Test INDEX_OP with a non integer index
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+				{Type: String, Value: "1"},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test INDEX_OP with a non integer index",
+		},
+		// Test INDEX_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test INDEX_OP with a non list type",
+		},
+		// Test INDEX_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test INDEX_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Index()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	var testCases = TestCase{
+		// Test APPEND_OP of a value
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expectedErr: nil,
+			title:       "Test APPEND_OP of a value",
+		},
+		// Test APPEND_OP to an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: String, Value: "a"},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}}},
+			},
+			expectedErr: nil,
+			title:       "Test APPEND_OP to an empty list",
+		},
+		// Test APPEND_OP of a list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: List, Value: []StackElement{{Type: Int, Value: 2}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test APPEND_OP of a list",
+		},
+		// Test APPEND_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test APPEND_OP with a non list type",
+		},
+		// Test APPEND_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test APPEND_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Append()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestListLiterals(t *testing.T) {
+	testCases := []struct {
+		title       string
+		source      string
+		expected    []StackElement
+		expectedErr error
+	}{
+		{
+			title:  "Test building a list",
+			source: `[ 1 2.5 "a" true ]`,
+			expected: []StackElement{{Type: List, Value: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Float, Value: 2.5},
+				{Type: String, Value: "a"},
+				{Type: Bool, Value: true},
+			}}},
+		},
+		{
+			title:    "Test building an empty list",
+			source:   `[ ]`,
+			expected: []StackElement{{Type: List, Value: []StackElement{}}},
+		},
+		{
+			title:  "Test building a nested list",
+			source: `[ [ 1 2 ] [ ] ]`,
+			expected: []StackElement{{Type: List, Value: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+				{Type: List, Value: []StackElement{}},
+			}}},
+		},
+		{
+			title:    "Test taking the length of a list",
+			source:   `[ 1 2 3 ] len`,
+			expected: []StackElement{{Type: Int, Value: 3}},
+		},
+		{
+			title:    "Test indexing element 1 of a list",
+			source:   `[ 10 20 30 ] 1 index swap drop`,
+			expected: []StackElement{{Type: Int, Value: 20}},
+		},
+		{
+			title:    "Test appending to a list",
+			source:   `[ 1 ] 2 append len`,
+			expected: []StackElement{{Type: Int, Value: 2}},
+		},
+		{
+			title:  "Test a list literal inside a loop is not shared between iterations",
+			source: `0 while dup 2 < do [ ] 1 pick append swap 1 + end drop`,
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 0}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+		},
+		{
+			title:       "Test an unclosed list",
+			source:      `[ 1 2`,
+			expectedErr: errors.New("ERROR: [ without a matching ]"),
+		},
+		{
+			title:       "Test a ] without a [",
+			source:      `1 2 ]`,
+			expectedErr: errors.New("ERROR: ] without a matching ["),
+		},
+		{
+			title:       "Test a list closed inside a quotation",
+			source:      `[ 1 { 2 ] }`,
+			expectedErr: errors.New("ERROR: { without a matching }"),
+		},
+		{
+			title:       "Test a quotation closed inside a list",
+			source:      `{ [ 1 } ]`,
+			expectedErr: errors.New("ERROR: [ without a matching ]"),
+		},
+		{
+			title:       "Test a list left open at the end of a procedure",
+			source:      `def f [ 1 end`,
+			expectedErr: errors.New("ERROR: [ without a matching ]"),
+		},
+		{
+			title:       "Test a list containing an operator",
+			source:      `[ 1 2 + ]`,
+			expectedErr: errors.New("ERROR: list literals can only contain values, but got operator(+)"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			stack, err := Run(tc.source, false, false)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+				return
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(stack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, stack)
+			}
+		})
+	}
+}
+
+func TestStdDev(t *testing.T) {
+	var testCases = TestCase{
+		// Test STDDEV_OP of a single element
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 5}}},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 0.0},
+			},
+			expectedErr: nil,
+			title:       "Test STDDEV_OP of a single element",
+		},
+		// Test STDDEV_OP of equal elements
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Float, Value: 1.5}, {Type: Float, Value: 1.5}}},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 0.0},
+			},
+			expectedErr: nil,
+			title:       "Test STDDEV_OP of equal elements",
+		},
+		// Test STDDEV_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot perform STDDEV_OP on an empty list"),
+			title:       "Test STDDEV_OP of an empty list",
+		},
+		// Test STDDEV_OP with non numeric elements
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test STDDEV_OP with non numeric elements",
+		},
+		// Test STDDEV_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test STDDEV_OP with a non list type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.StdDev()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestStdDevKnownDataset(t *testing.T) {
+	testCases := []struct {
+		title    string
+		source   string
+		expected float64
+	}{
+		{title: "Test STDDEV_OP of a known int dataset", source: `[ 2 4 4 4 5 5 7 9 ] stddev`, expected: 2.0},
+		{title: "Test STDDEV_OP of a mixed dataset", source: `[ 1 2.5 4 ] stddev`, expected: math.Sqrt(1.5)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			stack, err := Run(tc.source, false, false)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(stack) != 1 || stack[0].Type != Float {
+				t.Fatalf("Expected a single float, but got: %v", stack)
+			}
+
+			if math.Abs(stack[0].Value.(float64)-tc.expected) > 1e-9 {
+				t.Errorf("Expected %v, but got: %v", tc.expected, stack[0].Value)
+			}
+		})
+	}
+}
+
+func TestSubstr(t *testing.T) {
+	var testCases = TestCase{
+		// Test SUBSTR_OP of the middle of a string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello"},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 3},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "ell"},
+			},
+			expectedErr: nil,
+			title:       "Test SUBSTR_OP of the middle of a string",
+		},
+		// Test SUBSTR_OP of the whole string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello"},
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "hello"},
+			},
+			expectedErr: nil,
+			title:       "Test SUBSTR_OP of the whole string",
+		},
+		// Test SUBSTR_OP with a zero length
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello"},
+				{Type: Int, Value: 5},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: String, Value: ""},
+			},
+			expectedErr: nil,
+			title:       "Test SUBSTR_OP with a zero length",
+		},
+		// Test SUBSTR_OP does not split runes
+		{
+			stack: []StackElement{
+				{Type: String, Value: "héllo"},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "él"},
+			},
+			expectedErr: nil,
+			title:       "Test SUBSTR_OP does not split runes",
+		},
+		// Test SUBSTR_OP of a variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "s"},
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 2},
+			},
+			variableMap: map[string]Variable{
+				"s": {Name: "s", Type: String, Value: "gorth"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "go"},
+			},
+			expectedErr: nil,
+			title:       "Test SUBSTR_OP of a variable",
+		},
+		// Test SUBSTR_OP past the end of the string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello"},
+				{Type: Int, Value: 3},
+				{Type: Int, Value: 5},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: SUBSTR_OP range 3 to 8 is out of range for a string of 5 runes"),
+			title:       "Test SUBSTR_OP past the end of the string",
+		},
+		// Test SUBSTR_OP with a start past the end of the string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello"},
+				{Type: Int, Value: 6},
+				{Type: Int, Value: 0},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: SUBSTR_OP range 6 to 6 is out of range for a string of 5 runes"),
+			title:       "Test SUBSTR_OP with a start past the end of the string",
+		},
+		// Test SUBSTR_OP with a negative start
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello"},
+				{Type: Int, Value: -1},
+				{Type: Int, Value: 2},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: SUBSTR_OP range -1 to 1 is out of range for a string of 5 runes"),
+			title:       "Test SUBSTR_OP with a negative start",
+		},
+		// Test SUBSTR_OP with a negative length
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello"},
+				{Type: Int, Value: 1},
+				{Type: Int, Value: -1},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: SUBSTR_OP range 1 to 0 is out of range for a string of 5 runes"),
+			title:       "Test SUBSTR_OP with a negative length",
+		},
+		// Test SUBSTR_OP with a non string type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 0},
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test SUBSTR_OP with a non string type",
+		},
+		// Test SUBSTR_OP with too few elements
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: Int, Value: 0},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test SUBSTR_OP with too few elements",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Substr()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	var testCases = TestCase{
+		// Test HISTOGRAM_OP of a small dataset
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 5}, {Type: Int, Value: 11}, {Type: Int, Value: 4}, {Type: Int, Value: 13}}},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 0}, {Type: Int, Value: 3}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 5}, {Type: Int, Value: 1}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 10}, {Type: Int, Value: 2}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test HISTOGRAM_OP of a small dataset",
+		},
+		// Test HISTOGRAM_OP with negative and float elements
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: -1}, {Type: Float, Value: 0.5}, {Type: Float, Value: -2.5}, {Type: Int, Value: 3}}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: -4}, {Type: Int, Value: 1}}}, {Type: List, Value: []StackElement{{Type: Int, Value: -2}, {Type: Int, Value: 1}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 0}, {Type: Int, Value: 1}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 2}, {Type: Int, Value: 1}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test HISTOGRAM_OP with negative and float elements",
+		},
+		// Test HISTOGRAM_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test HISTOGRAM_OP of an empty list",
+		},
+		// Test HISTOGRAM_OP with a zero width
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: errors.New("ERROR: HISTOGRAM_OP expects a positive bucket width"),
+			title:       "Test HISTOGRAM_OP with a zero width",
+		},
+		// Test HISTOGRAM_OP with a negative width
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: -5},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: errors.New("ERROR: HISTOGRAM_OP expects a positive bucket width"),
+			title:       "Test HISTOGRAM_OP with a negative width",
+		},
+		// Test HISTOGRAM_OP with a non integer width
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Float, Value: 2.5},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test HISTOGRAM_OP with a non integer width",
+		},
+		// Test HISTOGRAM_OP with non numeric elements
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+				{Type: Int, Value: 5},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test HISTOGRAM_OP with non numeric elements",
+		},
+		// Test HISTOGRAM_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 5},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test HISTOGRAM_OP with a non list type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Histogram()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestUpper(t *testing.T) {
+	var testCases = TestCase{
+		// Test UPPER_OP of a string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "Hello"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "HELLO"},
+			},
+			expectedErr: nil,
+			title:       "Test UPPER_OP of a string",
+		},
+		// Test UPPER_OP of a multibyte string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "École"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "ÉCOLE"},
+			},
+			expectedErr: nil,
+			title:       "Test UPPER_OP of a multibyte string",
+		},
+		// Test UPPER_OP of a string variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "s"},
+			},
+			variableMap: map[string]Variable{
+				"s": {Name: "s", Type: String, Value: "GoRth"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "GORTH"},
+			},
+			expectedErr: nil,
+			title:       "Test UPPER_OP of a string variable",
+		},
+		// Test UPPER_OP of an int
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test UPPER_OP of an int",
+		},
+		// Test UPPER_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test UPPER_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Upper()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLower(t *testing.T) {
+	var testCases = TestCase{
+		// Test LOWER_OP of a string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "Hello"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "hello"},
+			},
+			expectedErr: nil,
+			title:       "Test LOWER_OP of a string",
+		},
+		// Test LOWER_OP of a multibyte string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "École"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "école"},
+			},
+			expectedErr: nil,
+			title:       "Test LOWER_OP of a multibyte string",
+		},
+		// Test LOWER_OP of a string variable
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "s"},
+			},
+			variableMap: map[string]Variable{
+				"s": {Name: "s", Type: String, Value: "GoRth"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "gorth"},
+			},
+			expectedErr: nil,
+			title:       "Test LOWER_OP of a string variable",
+		},
+		// Test LOWER_OP of an int
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LOWER_OP of an int",
+		},
+		// Test LOWER_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test LOWER_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Lower()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestEnumerate(t *testing.T) {
+	var testCases = TestCase{
+		// Test ENUMERATE_OP of a list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: String, Value: "b"}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 0}, {Type: String, Value: "a"}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "b"}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test ENUMERATE_OP of a list",
+		},
+		// Test ENUMERATE_OP of a nested list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 5}}}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 0}, {Type: List, Value: []StackElement{{Type: Int, Value: 5}}}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test ENUMERATE_OP of a nested list",
+		},
+		// Test ENUMERATE_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test ENUMERATE_OP of an empty list",
+		},
+		// Test ENUMERATE_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: String, Value: "ab"},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test ENUMERATE_OP with a non list type",
+		},
+		// Test ENUMERATE_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test ENUMERATE_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Enumerate()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	var testCases = TestCase{
+		// Test SPLIT_OP of a comma separated string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a,b,c"},
+				{Type: String, Value: ","},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: String, Value: "b"}, {Type: String, Value: "c"}}},
+			},
+			expectedErr: nil,
+			title:       "Test SPLIT_OP of a comma separated string",
+		},
+		// Test SPLIT_OP with a multi character delimiter
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a::b"},
+				{Type: String, Value: "::"},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: String, Value: "b"}}},
+			},
+			expectedErr: nil,
+			title:       "Test SPLIT_OP with a multi character delimiter",
+		},
+		// Test SPLIT_OP keeps empty parts
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a,,b,"},
+				{Type: String, Value: ","},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: String, Value: ""}, {Type: String, Value: "b"}, {Type: String, Value: ""}}},
+			},
+			expectedErr: nil,
+			title:       "Test SPLIT_OP keeps empty parts",
+		},
+		// Test SPLIT_OP without the delimiter
+		{
+			stack: []StackElement{
+				{Type: String, Value: "abc"},
+				{Type: String, Value: ","},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "abc"}}},
+			},
+			expectedErr: nil,
+			title:       "Test SPLIT_OP without the delimiter",
+		},
+		// Test SPLIT_OP with an empty delimiter
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hé!"},
+				{Type: String, Value: ""},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "h"}, {Type: String, Value: "é"}, {Type: String, Value: "!"}}},
+			},
+			expectedErr: nil,
+			title:       "Test SPLIT_OP with an empty delimiter",
+		},
+		// Test SPLIT_OP of an empty string
+		{
+			stack: []StackElement{
+				{Type: String, Value: ""},
+				{Type: String, Value: ","},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test SPLIT_OP of an empty string",
+		},
+		// Test SPLIT_OP with a non string delimiter
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a1b"},
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test SPLIT_OP with a non string delimiter",
+		},
+		// Test SPLIT_OP of a non string
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: String, Value: ","},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test SPLIT_OP of a non string",
+		},
+		// Test SPLIT_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: String, Value: ","},
+			},
+			expected: []StackElement{
+				{Type: String, Value: ","},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test SPLIT_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Split()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestSplitProgram(t *testing.T) {
+	stack, err := Run(`"a,b,c" "," split dup len swap 2 index swap drop`, false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: Int, Value: 3}, {Type: String, Value: "c"}}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, stack)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	var testCases = TestCase{
+		// Test CHUNK_OP of an evenly divisible list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 4}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test CHUNK_OP of an evenly divisible list",
+		},
+		// Test CHUNK_OP with a remainder
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}, {Type: Int, Value: 4}, {Type: Int, Value: 5}}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 4}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 5}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test CHUNK_OP with a remainder",
+		},
+		// Test CHUNK_OP with a size larger than the list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+				{Type: Int, Value: 5},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test CHUNK_OP with a size larger than the list",
+		},
+		// Test CHUNK_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: Int, Value: 2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test CHUNK_OP of an empty list",
+		},
+		// Test CHUNK_OP with a zero size
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: 0},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: errors.New("ERROR: CHUNK_OP expects a positive size"),
+			title:       "Test CHUNK_OP with a zero size",
+		},
+		// Test CHUNK_OP with a negative size
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: -2},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: errors.New("ERROR: CHUNK_OP expects a positive size"),
+			title:       "Test CHUNK_OP with a negative size",
+		},
+		// Test CHUNK_OP with a non integer size
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: String, Value: "2"},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test CHUNK_OP with a non integer size",
+		},
+		// Test CHUNK_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+				{Type: Int, Value: 2},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test CHUNK_OP with a non list type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Chunk()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	var testCases = TestCase{
+		// Test JOIN_OP of a list of strings
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: String, Value: "b"}, {Type: String, Value: "c"}}},
+				{Type: String, Value: ","},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a,b,c"},
+			},
+			expectedErr: nil,
+			title:       "Test JOIN_OP of a list of strings",
+		},
+		// Test JOIN_OP with an empty separator
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "go"}, {Type: String, Value: "rth"}}},
+				{Type: String, Value: ""},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "gorth"},
+			},
+			expectedErr: nil,
+			title:       "Test JOIN_OP with an empty separator",
+		},
+		// Test JOIN_OP of a single string
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}}},
+				{Type: String, Value: ", "},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expectedErr: nil,
+			title:       "Test JOIN_OP of a single string",
+		},
+		// Test JOIN_OP of an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: String, Value: ","},
+			},
+			expected: []StackElement{
+				{Type: String, Value: ""},
+			},
+			expectedErr: nil,
+			title:       "Test JOIN_OP of an empty list",
+		},
+		// Test JOIN_OP of a mixed type list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: Int, Value: 1}}},
+				{Type: String, Value: ","},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test JOIN_OP of a mixed type list",
+		},
+		// Test JOIN_OP with a non string separator
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}}},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test JOIN_OP with a non string separator",
+		},
+		// Test JOIN_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: String, Value: "a"},
+				{Type: String, Value: ","},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test JOIN_OP with a non list type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Join()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestSplitJoinProgram(t *testing.T) {
+	stack, err := Run(`"a,b,c" "," split "-" join`, false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{{Type: String, Value: "a-b-c"}}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, stack)
+	}
+}
+
+func TestLDiff(t *testing.T) {
+	var testCases = TestCase{
+		// Test LDIFF_OP of overlapping lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 3}}},
+			},
+			expectedErr: nil,
+			title:       "Test LDIFF_OP of overlapping lists",
+		},
+		// Test LDIFF_OP of disjoint lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 3}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test LDIFF_OP of disjoint lists",
+		},
+		// Test LDIFF_OP removes duplicates
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 1}}},
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test LDIFF_OP removes duplicates",
+		},
+		// Test LDIFF_OP compares with ==
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Float, Value: 2.0}, {Type: String, Value: "a"}}},
+				{Type: List, Value: []StackElement{{Type: Float, Value: 1.0}, {Type: String, Value: "a"}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Float, Value: 2.0}}},
+			},
+			expectedErr: nil,
+			title:       "Test LDIFF_OP compares with ==",
+		},
+		// Test LDIFF_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LDIFF_OP with a non list type",
+		},
+		// Test LDIFF_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test LDIFF_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.LDiff()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLUnion(t *testing.T) {
+	var testCases = TestCase{
+		// Test LUNION_OP of overlapping lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expectedErr: nil,
+			title:       "Test LUNION_OP of overlapping lists",
+		},
+		// Test LUNION_OP of disjoint lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: String, Value: "a"}}},
+			},
+			expectedErr: nil,
+			title:       "Test LUNION_OP of disjoint lists",
+		},
+		// Test LUNION_OP removes duplicates
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}, {Type: Int, Value: 2}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 1}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}, {Type: Int, Value: 1}}},
+			},
+			expectedErr: nil,
+			title:       "Test LUNION_OP removes duplicates",
+		},
+		// Test LUNION_OP of empty lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test LUNION_OP of empty lists",
+		},
+		// Test LUNION_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LUNION_OP with a non list type",
+		},
+		// Test LUNION_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test LUNION_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.LUnion()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestLIntersect(t *testing.T) {
+	var testCases = TestCase{
+		// Test LINTERSECT_OP of overlapping lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 2}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expectedErr: nil,
+			title:       "Test LINTERSECT_OP of overlapping lists",
+		},
+		// Test LINTERSECT_OP of disjoint lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 3}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test LINTERSECT_OP of disjoint lists",
+		},
+		// Test LINTERSECT_OP removes duplicates
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 1}, {Type: Int, Value: 2}}},
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: nil,
+			title:       "Test LINTERSECT_OP removes duplicates",
+		},
+		// Test LINTERSECT_OP of nested lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 2}}}}},
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 2}}}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 2}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test LINTERSECT_OP of nested lists",
+		},
+		// Test LINTERSECT_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+				{Type: Int, Value: 1},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test LINTERSECT_OP with a non list type",
+		},
+		// Test LINTERSECT_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}}},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test LINTERSECT_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.LIntersect()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestContains(t *testing.T) {
+	var testCases = TestCase{
+		// Test CONTAINS_OP with a contained string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello world"},
+				{Type: String, Value: "world"},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test CONTAINS_OP with a contained string",
+		},
+		// Test CONTAINS_OP with a missing string
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello world"},
+				{Type: String, Value: "planet"},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test CONTAINS_OP with a missing string",
+		},
+		// Test CONTAINS_OP is case sensitive
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello world"},
+				{Type: String, Value: "World"},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: false},
+			},
+			expectedErr: nil,
+			title:       "Test CONTAINS_OP is case sensitive",
+		},
+		// Test CONTAINS_OP with an empty needle
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello"},
+				{Type: String, Value: ""},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test CONTAINS_OP with an empty needle",
+		},
+		// Test CONTAINS_OP with identifiers
+		{
+			stack: []StackElement{
+				{Type: Identifier, Value: "haystack"},
+				{Type: Identifier, Value: "needle"},
+			},
+			variableMap: map[string]Variable{
+				"haystack": {Name: "haystack", Type: String, Value: "gorth"},
+				"needle":   {Name: "needle", Type: String, Value: "ort"},
+			},
+			expected: []StackElement{
+				{Type: Bool, Value: true},
+			},
+			expectedErr: nil,
+			title:       "Test CONTAINS_OP with identifiers",
+		},
+		// Test CONTAINS_OP with a non string type
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello"},
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test CONTAINS_OP with a non string type",
+		},
+		// Test CONTAINS_OP with a single element
+		{
+			stack: []StackElement{
+				{Type: String, Value: "hello"},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "hello"},
+			},
+			expectedErr: ErrStackEmpty,
+			title:       "Test CONTAINS_OP with a single element",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Contains()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestToMap(t *testing.T) {
+	var testCases = TestCase{
+		// Test TOMAP_OP with a list of pairs
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: Int, Value: 1}}}, {Type: List, Value: []StackElement{{Type: String, Value: "b"}, {Type: String, Value: "two"}}}}},
+			},
+			expected: []StackElement{
+				{Type: Map, Value: map[string]StackElement{"a": {Type: Int, Value: 1}, "b": {Type: String, Value: "two"}}},
+			},
+			expectedErr: nil,
+			title:       "Test TOMAP_OP with a list of pairs",
+		},
+		// Test TOMAP_OP with an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: Map, Value: map[string]StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test TOMAP_OP with an empty list",
+		},
+		// Test TOMAP_OP with a duplicate key, the last value wins
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: Int, Value: 1}}}, {Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: Int, Value: 2}}}}},
+			},
+			expected: []StackElement{
+				{Type: Map, Value: map[string]StackElement{"a": {Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test TOMAP_OP with a duplicate key",
+		},
+		// Test TOMAP_OP with a list value
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}}}}}},
+			},
+			expected: []StackElement{
+				{Type: Map, Value: map[string]StackElement{"a": {Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}}}},
+			},
+			expectedErr: nil,
+			title:       "Test TOMAP_OP with a list value",
+		},
+		// Test TOMAP_OP with a malformed pair
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: Int, Value: 1}}}, {Type: List, Value: []StackElement{{Type: String, Value: "b"}}}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: TOMAP_OP expects a list of [ key value ] pairs, but got list([string(\"b\")])"),
+			title:       "Test TOMAP_OP with a malformed pair",
+		},
+		// Test TOMAP_OP with a non list pair
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: TOMAP_OP expects a list of [ key value ] pairs, but got string(\"a\")"),
+			title:       "Test TOMAP_OP with a non list pair",
+		},
+		// Test TOMAP_OP with a non string key
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}}}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test TOMAP_OP with a non string key",
+		},
+		// Test TOMAP_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test TOMAP_OP with a non list type",
+		},
+		// Test TOMAP_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test TOMAP_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.ToMap()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestToMapProgram(t *testing.T) {
+	stack, err := Run(`[ [ "a" 1 ] [ "b" 2 ] ] tomap dup [ [ "b" 2 ] [ "a" 1 ] ] tomap ==`, false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{
+		{Type: Map, Value: map[string]StackElement{"a": {Type: Int, Value: 1}, "b": {Type: Int, Value: 2}}},
+		{Type: Bool, Value: true},
+	}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, stack)
+	}
+}
+
+func TestMKeys(t *testing.T) {
+	var testCases = TestCase{
+		// Test MKEYS_OP pushes the keys in sorted order
+		{
+			stack: []StackElement{
+				{Type: Map, Value: map[string]StackElement{"b": {Type: Int, Value: 2}, "a": {Type: List, Value: []StackElement{{Type: Int, Value: 1}}}, "c": {Type: String, Value: "three"}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: String, Value: "b"}, {Type: String, Value: "c"}}},
+			},
+			expectedErr: nil,
+			title:       "Test MKEYS_OP pushes the keys in sorted order",
+		},
+		// Test MKEYS_OP with an empty map
+		{
+			stack: []StackElement{
+				{Type: Map, Value: map[string]StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test MKEYS_OP with an empty map",
+		},
+		// Test MKEYS_OP with a non map type
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}}},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test MKEYS_OP with a non map type",
+		},
+		// Test MKEYS_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test MKEYS_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.MKeys()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestMValues(t *testing.T) {
+	var testCases = TestCase{
+		// Test MVALUES_OP pushes the values in the sorted order of their keys
+		{
+			stack: []StackElement{
+				{Type: Map, Value: map[string]StackElement{"b": {Type: Int, Value: 2}, "a": {Type: List, Value: []StackElement{{Type: Int, Value: 1}}}, "c": {Type: String, Value: "three"}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}}}, {Type: Int, Value: 2}, {Type: String, Value: "three"}}},
+			},
+			expectedErr: nil,
+			title:       "Test MVALUES_OP pushes the values in the sorted order of their keys",
+		},
+		// Test MVALUES_OP with an empty map
+		{
+			stack: []StackElement{
+				{Type: Map, Value: map[string]StackElement{}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expectedErr: nil,
+			title:       "Test MVALUES_OP with an empty map",
+		},
+		// Test MVALUES_OP with a non map type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test MVALUES_OP with a non map type",
+		},
+		// Test MVALUES_OP with an empty stack
+		{
+			stack:       []StackElement{},
+			expected:    []StackElement{},
+			expectedErr: ErrStackEmpty,
+			title:       "Test MVALUES_OP with an empty stack",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.MValues()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestMKeysAndMValuesProgram(t *testing.T) {
+	stack, err := Run(`[ [ "b" 2 ] [ "a" 1 ] [ "b" 3 ] ] tomap dup mkeys swap mvalues`, false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []StackElement{
+		{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: String, Value: "b"}}},
+		{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 3}}},
+	}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected stack: %v, but got: %v", expected, stack)
+	}
+}
+
+func TestDisabledOps(t *testing.T) {
+	testCases := []struct {
+		title       string
+		source      string
+		expected    []StackElement
+		expectedErr error
+	}{
+		{
+			title:       "Test using a disabled operator",
+			source:      `1 2 +`,
+			expectedErr: errors.New("ERROR: operator + is disabled"),
+		},
+		{
+			title:    "Test other operators still work",
+			source:   `3 2 - dup *`,
+			expected: []StackElement{{Type: Int, Value: 1}},
+		},
+		{
+			title:       "Test a disabled operator is rejected before the program runs",
+			source:      `"before" print 10 sleep`,
+			expectedErr: errors.New("ERROR: operator sleep is disabled"),
+		},
+		{
+			title:       "Test a disabled operator inside a quotation that never runs",
+			source:      `1 { 2 + } drop`,
+			expectedErr: errors.New("ERROR: operator + is disabled"),
+		},
+		{
+			title:       "Test a disabled operator inside a procedure",
+			source:      `def add2 2 + end 1`,
+			expectedErr: errors.New("ERROR: operator + is disabled"),
+		},
+		{
+			title:       "Test a disabled operator run with apply",
+			source:      `1 2 "+" apply`,
+			expectedErr: errors.New("ERROR: operator + is disabled"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			var out bytes.Buffer
+			g := NewGorth(false, false)
+			g.Out = &out
+			g.DisabledOps = map[Operation]bool{ADD_OP: true, SLEEP_OP: true}
+
+			err := g.Run(tc.source)
+			if !reflect.DeepEqual(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, but got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr == nil && !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+			if out.Len() > 0 {
+				t.Errorf("Expected no output, but got: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestMode(t *testing.T) {
+	var testCases = TestCase{
+		// Test MODE_OP with a clear mode
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Int, Value: 2}, {Type: Int, Value: 2}, {Type: Int, Value: 3}}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 2},
+			},
+			expectedErr: nil,
+			title:       "Test MODE_OP with a clear mode",
+		},
+		// Test MODE_OP with a tie, the first seen element wins
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 3}, {Type: Int, Value: 1}, {Type: Int, Value: 1}, {Type: Int, Value: 3}, {Type: Int, Value: 2}}},
+			},
+			expected: []StackElement{
+				{Type: Int, Value: 3},
+			},
+			expectedErr: nil,
+			title:       "Test MODE_OP with a tie",
+		},
+		// Test MODE_OP with a single element list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}}},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expectedErr: nil,
+			title:       "Test MODE_OP with a single element list",
+		},
+		// Test MODE_OP compares with ==
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 1}, {Type: Float, Value: 2.0}, {Type: Int, Value: 2}}},
+			},
+			expected: []StackElement{
+				{Type: Float, Value: 2.0},
+			},
+			expectedErr: nil,
+			title:       "Test MODE_OP compares with ==",
+		},
+		// Test MODE_OP with mixed types
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: String, Value: "a"}, {Type: Int, Value: 1}, {Type: Bool, Value: true}, {Type: String, Value: "a"}}},
+			},
+			expected: []StackElement{
+				{Type: String, Value: "a"},
+			},
+			expectedErr: nil,
+			title:       "Test MODE_OP with mixed types",
+		},
+		// Test MODE_OP with nested lists
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{{Type: List, Value: []StackElement{{Type: Int, Value: 1}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 2}}}, {Type: List, Value: []StackElement{{Type: Int, Value: 2}}}}},
+			},
+			expected: []StackElement{
+				{Type: List, Value: []StackElement{{Type: Int, Value: 2}}},
+			},
+			expectedErr: nil,
+			title:       "Test MODE_OP with nested lists",
+		},
+		// Test MODE_OP with an empty list
+		{
+			stack: []StackElement{
+				{Type: List, Value: []StackElement{}},
+			},
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: cannot perform MODE_OP on an empty list"),
+			title:       "Test MODE_OP with an empty list",
+		},
+		// Test MODE_OP with a non list type
+		{
+			stack: []StackElement{
+				{Type: Int, Value: 1},
+			},
+			expected:    []StackElement{},
+			expectedErr: ErrTypeMismatch,
+			title:       "Test MODE_OP with a non list type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.ExecStack = tc.stack
+			g.VariableMap = tc.variableMap
+
+			err := g.Mode()
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+		})
+	}
+}
+
+func TestDynamicTyping(t *testing.T) {
+	testCases := []struct {
+		title            string
+		source           string
+		dynamicTyping    bool
+		expectedVariable Variable
+		expectedErr      error
+	}{
+		{
+			title:            "Test assigning a string to an int variable with static typing",
+			source:           `/x 1 def _x "hi" =`,
+			expectedVariable: Variable{Name: "x", Type: Int, Value: 1},
+			expectedErr:      errors.New("ERROR: cannot assign a non-integer value to an integer variable"),
+		},
+		{
+			title:            "Test assigning a string to an int variable with dynamic typing",
+			source:           `/x 1 def _x "hi" =`,
+			dynamicTyping:    true,
+			expectedVariable: Variable{Name: "x", Type: String, Value: "hi"},
+		},
+		{
+			title:            "Test assigning the same type with dynamic typing",
+			source:           `/x 1 def _x 2 =`,
+			dynamicTyping:    true,
+			expectedVariable: Variable{Name: "x", Type: Int, Value: 2},
+		},
+		{
+			title:            "Test assigning to a constant with dynamic typing",
+			source:           `/x 1 const _x "hi" =`,
+			dynamicTyping:    true,
+			expectedVariable: Variable{Name: "x", Type: Int, Value: 1, Const: true},
+			expectedErr:      errors.New("ERROR: variable x is a constant and cannot be reassigned"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+			g.DynamicTyping = tc.dynamicTyping
+
+			err := g.Run(tc.source)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if variable := g.VariableMap["x"]; !reflect.DeepEqual(variable, tc.expectedVariable) {
+				t.Errorf("Expected variable: %v, but got: %v", tc.expectedVariable, variable)
+			}
+		})
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	testCases := []struct {
+		title       string
+		flags       []string
+		expected    cliOptions
+		expectedErr error
+	}{
+		{
+			title:    "Test no flags",
+			flags:    []string{},
+			expected: cliOptions{},
+		},
+		{
+			title:    "Test every flag together",
+			flags:    []string{"-d", "-s", "-t", "--metrics-json"},
+			expected: cliOptions{debugMode: true, strictMode: true, dynamicTyping: true, metricsJSON: true},
+		},
+		{
+			title:    "Test flags in any order",
+			flags:    []string{"--metrics-json", "-t"},
+			expected: cliOptions{dynamicTyping: true, metricsJSON: true},
+		},
+		{
+			title:       "Test an invalid flag",
+			flags:       []string{"-d", "-x"},
+			expectedErr: errors.New("Invalid option: -x"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			options, err := parseOptions(tc.flags)
+			if !reflect.DeepEqual(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, but got: %v", tc.expectedErr, err)
+			}
+			if options != tc.expected {
+				t.Errorf("Expected options: %+v, but got: %+v", tc.expected, options)
+			}
+		})
+	}
+}
+
+// sentinels are the errors that errorMatches checks with errors.Is
+var sentinels = []error{ErrStackOverflow, ErrStackEmpty, ErrDivideByZero, ErrTypeMismatch, ErrUndeclaredVariable}
+
+// errorMatches reports whether err is the expected error, a sentinel such as ErrStackEmpty is
+// matched with errors.Is and any other error by its message
+func errorMatches(err, expected error) bool {
+	for _, sentinel := range sentinels {
+		if expected == sentinel {
+			return errors.Is(err, expected)
+		}
+	}
+
+	return err.Error() == expected.Error()
+}
+
+func TestScopes(t *testing.T) {
+	testCases := []struct {
+		title       string
+		source      string
+		expected    []StackElement
+		expectedErr error
+	}{
+		{
+			title:       "Test a variable declared in a scope is undeclared after it",
+			source:      `scope /x 1 def end _x`,
+			expected:    []StackElement{},
+			expectedErr: ErrUndeclaredVariable,
+		},
+		{
+			title:       "Test a variable left on the stack by a scope cannot be resolved after it",
+			source:      `scope /x 5 def end 1 +`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: variable x has not been declared"),
+		},
+		{
+			title:    "Test using a variable inside its scope",
+			source:   `scope /x 5 def _x _x * swap drop end`,
+			expected: []StackElement{{Type: Int, Value: 25}},
+		},
+		{
+			title:  "Test a scope shadowing an outer variable",
+			source: `/x 1 def scope /x 2 def _x 10 * end _x 10 *`,
+			expected: []StackElement{
+				{Type: Identifier, Value: "x"},
+				{Type: Identifier, Value: "x"},
+				{Type: Int, Value: 20},
+				{Type: Int, Value: 10},
+			},
+		},
+		{
+			title:    "Test an inner scope using a variable from an outer scope",
+			source:   `scope /x 2 def scope _x 3 * end end`,
+			expected: []StackElement{{Type: Identifier, Value: "x"}, {Type: Int, Value: 6}},
+		},
+		{
+			title:    "Test assigning to an outer variable inside a scope",
+			source:   `/x 1 def scope _x 5 = end _x 1 +`,
+			expected: []StackElement{{Type: Identifier, Value: "x"}, {Type: Int, Value: 6}},
+		},
+		{
+			title:       "Test reassigning a constant declared in a scope",
+			source:      `scope /x 1 const _x 2 = end`,
+			expected:    []StackElement{{Type: Identifier, Value: "x"}},
+			expectedErr: errors.New("ERROR: variable x is a constant and cannot be reassigned"),
+		},
+		{
+			title:       "Test declaring a variable twice in the same scope",
+			source:      `scope /x 1 def /x 2 def end`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("variable x has already been declared"),
+		},
+		{
+			title:    "Test a scope in a loop body is new on every iteration",
+			source:   `3 while dup 0 > do scope /n 1 def drop end 1 - end`,
+			expected: []StackElement{{Type: Int, Value: 0}},
+		},
+		{
+			title:    "Test a scope inside a procedure",
+			source:   `def f scope /y 2 def swap _y * swap drop end end 4 f`,
+			expected: []StackElement{{Type: Int, Value: 8}},
+		},
+		{
+			title:       "Test a scope without an end",
+			source:      `scope 1`,
+			expected:    []StackElement{},
+			expectedErr: errors.New("ERROR: scope without a matching end"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			g := NewGorth(false, false)
+
+			err := g.Run(tc.source)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if !errorMatches(err, tc.expectedErr) {
+					t.Errorf("Expected error: %q, but got: %q", tc.expectedErr, err)
+				}
+			} else if tc.expectedErr != nil {
+				t.Errorf("Expected error: %q, but got nil", tc.expectedErr)
+			}
+
+			if !reflect.DeepEqual(g.ExecStack, tc.expected) {
+				t.Errorf("Expected stack: %v, but got: %v", tc.expected, g.ExecStack)
+			}
+
+			if len(g.scopes) != 0 {
+				t.Errorf("Expected every scope to be closed, but %d are open", len(g.scopes))
+			}
+		})
+	}
+}
+
+func TestScopeClosedByReturn(t *testing.T) {
+	g := NewGorth(false, false)
+
+	err := g.Run(`def f scope /y 1 def return end end f`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, exists := g.lookupVariable("y"); exists {
+		t.Errorf("Expected y to be undeclared after its scope was left with return")
+	}
+
+	if len(g.scopes) != 0 {
+		t.Errorf("Expected every scope to be closed, but %d are open", len(g.scopes))
+	}
+}